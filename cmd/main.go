@@ -2,51 +2,224 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"goconverter/internal/config"
 	"goconverter/internal/converter"
 	"goconverter/internal/fetcher"
+	"goconverter/internal/server"
 	"goconverter/internal/subscription/parser"
+	"goconverter/internal/subscription/processor"
 	"log"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 )
 
+// urlList 可重复指定的 -url 参数
+type urlList []string
+
+func (l *urlList) String() string {
+	return strings.Join(*l, "|")
+}
+
+func (l *urlList) Set(value string) error {
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// headerList 可重复指定的 -header 参数
+type headerList []string
+
+func (l *headerList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *headerList) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("invalid header %q", value)
+	}
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
 	// 定义命令行参数
-	subscriptionURL := flag.String("url", "", "订阅地址URL")
-	configURL := flag.String("config", "https://raw.githubusercontent.com/ACL4SSR/ACL4SSR/refs/heads/master/Clash/config/ACL4SSR.ini", "配置文件URL")
+	var subscriptionURLs urlList
+	flag.Var(&subscriptionURLs, "url", "订阅地址URL或本地路径，可重复指定或用 | 分隔，支持 tag:名称,URL")
+	configURL := flag.String("config", "https://raw.githubusercontent.com/ACL4SSR/ACL4SSR/refs/heads/master/Clash/config/ACL4SSR.ini", "配置文件URL，也可以是 file://、data: 或本地路径")
 	outputFile := flag.String("output", "", "输出文件路径(可选)")
-	// targetFormat := flag.String("target", "clash", "目标格式(clash/surge/quantumult)")
+	targetFormat := flag.String("target", "clash", "目标格式(clash/stash/surge/quanx/loon/singbox/mixed/ss/ssr/v2ray/trojan/vless/sip008)")
+	surgeVersion := flag.Int("ver", 5, "Surge 版本(3/4/5)")
+	templateFile := flag.String("template", "", "sing-box 基础模板文件路径(可选)")
+	subscriptionFormat := flag.String("format", parser.FormatAuto, "订阅格式(auto/clashx/line/base64/sip008/singbox)")
+	expandRules := flag.Bool("expand", true, "展开远程规则集，false 时输出为 Clash rule-providers；配置中的 inline: 与 clash-domain: 等前缀可单独指定")
+	include := flag.String("include", "", "保留备注匹配该正则的节点，默认使用配置中的 include_remarks")
+	exclude := flag.String("exclude", "", "排除备注匹配该正则的节点，默认使用配置中的 exclude_remarks")
+	protocols := flag.String("protocols", "", "只保留这些协议的节点，逗号分隔")
+	excludeProtocols := flag.String("exclude-protocols", "", "排除这些协议的节点，逗号分隔")
+	ports := flag.String("ports", "", "只保留端口在范围内的节点，! 开头的条目表示排除，如 443,8000-9000,!8080")
+	blockServers := flag.String("block-servers", "", "排除这些服务器的节点，逗号分隔的 CIDR 或域名后缀")
+	rename := flag.String("rename", "", "节点重命名规则 regex@replacement，多条用 ` 分隔，默认使用配置中的 rename_node")
+	addEmoji := flag.Bool("emoji", false, "为节点添加地区旗帜，默认使用配置中的 add_emoji")
+	removeEmoji := flag.Bool("remove-emoji", false, "去掉节点已有的 emoji，默认使用配置中的 remove_old_emoji")
+	cacheDir := flag.String("cache-dir", "", "下载缓存目录(可选，为空时只缓存在内存中)")
+	cacheTTL := flag.Duration("cache-ttl", time.Hour, "下载缓存有效期")
+	infoNode := flag.Bool("info-node", false, "在节点列表开头插入显示剩余流量与到期时间的节点")
+	userAgent := flag.String("user-agent", "", "下载订阅时使用的 User-Agent(可选)")
+	var headers headerList
+	flag.Var(&headers, "header", "下载时附加的请求头，格式为 Key: Value，可重复指定")
+	proxy := flag.String("proxy", "", "下载使用的代理(可选，如 http://127.0.0.1:7890、socks5://127.0.0.1:7891)")
+	timeout := flag.Duration("timeout", 20*time.Second, "单次下载超时时间")
+	rulesetWorkers := flag.Int("ruleset-workers", config.DefaultRulesetWorkers, "同时下载的规则集数量")
+	baseDir := flag.String("base-dir", "", "本地相对路径的基准目录(可选，默认为当前目录)")
+	listenAddr := flag.String("listen", "", "以服务方式运行的监听地址(可选，如 :25500)")
 
 	flag.Parse()
 
-	if *subscriptionURL == "" {
+	fetcherOptions := []fetcher.Option{fetcher.WithTimeout(*timeout), fetcher.WithBaseDir(*baseDir)}
+	if *userAgent != "" {
+		fetcherOptions = append(fetcherOptions, fetcher.WithUserAgent(*userAgent))
+	}
+	for _, header := range headers {
+		key, value, _ := strings.Cut(header, ":")
+		fetcherOptions = append(fetcherOptions, fetcher.WithHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+	if *proxy != "" {
+		proxyURL, err := url.Parse(*proxy)
+		if err != nil {
+			log.Fatalf("代理地址无效: %v", err)
+		}
+		fetcherOptions = append(fetcherOptions, fetcher.WithProxy(proxyURL))
+	}
+
+	if *listenAddr != "" {
+		log.Printf("监听地址: %s", *listenAddr)
+		if *cacheDir != "" {
+			fetcherOptions = append(fetcherOptions, fetcher.WithCache(server.NewCache(*cacheDir, *cacheTTL)))
+		}
+		srv := server.NewServer(*configURL, fetcherOptions...)
+		srv.SetRulesetWorkers(*rulesetWorkers)
+		log.Fatal(srv.Run(*listenAddr))
+	}
+
+	if len(subscriptionURLs) == 0 {
 		log.Fatal("订阅地址不能为空")
 	}
 
-	contentFetcher := fetcher.NewFetcher()
-	configBytes, err := contentFetcher.Fetch(*configURL)
+	fetcherOptions = append(fetcherOptions, fetcher.WithCache(fetcher.NewCache(*cacheDir, *cacheTTL)))
+	contentFetcher := fetcher.NewFetcher(fetcherOptions...)
+	cfg, err := config.LoadConfigContext(context.Background(), contentFetcher, *configURL, *rulesetWorkers)
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	sources := make([]processor.Source, 0, len(subscriptionURLs))
+	for _, value := range subscriptionURLs {
+		name, subscriptionURL := processor.ParseSource(value)
+		resp, err := contentFetcher.FetchWithMeta(context.Background(), subscriptionURL)
+		if err != nil {
+			log.Fatalf("加载订阅失败: %v", err)
+		}
+		content := string(resp.Body)
+		parsed, format, err := parser.ParseSubscriptionWithFormat(content, *subscriptionFormat)
+		if err != nil {
+			log.Printf("解析订阅 %s 失败: %v", subscriptionURL, err)
+		} else {
+			log.Printf("订阅 %s 格式: %s", subscriptionURL, format)
+		}
+		info := parser.ParseUserInfo(resp.Header.Get(parser.UserInfoHeader))
+		if info == nil && format == parser.FormatSIP008 {
+			info = parser.UserInfoFromSIP008(content)
+		}
+		sources = append(sources, processor.Source{Name: name, Nodes: parsed, Info: info})
+	}
+	nodes := processor.Merge(sources)
+	info := processor.MergeUserInfo(sources)
+	if info != nil {
+		log.Printf("订阅信息: %s (%s)", info.Summary(), info)
+	}
 
-	cfg, err := config.ParseConfig(configBytes)
-	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+	filterOptions := processor.FilterOptions{
+		IncludeRemarks:   cfg.IncludeRemarks,
+		ExcludeRemarks:   cfg.ExcludeRemarks,
+		Protocols:        []string{*protocols},
+		ExcludeProtocols: []string{*excludeProtocols},
+		Ports:            *ports,
+		BlockServers:     strings.Split(*blockServers, ","),
+	}
+	if *include != "" {
+		filterOptions.IncludeRemarks = []string{*include}
+	}
+	if *exclude != "" {
+		filterOptions.ExcludeRemarks = []string{*exclude}
 	}
-	subscriptionBytes, err := contentFetcher.Fetch(*subscriptionURL)
+	filter, err := processor.NewFilter(filterOptions)
 	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+		log.Fatalf("过滤条件无效: %v", err)
 	}
-	nodes, _ := parser.ParseSubscription(string(subscriptionBytes), "clashx")
+	nodes = filter.Apply(nodes)
 
-	conv := converter.NewClashConverter(&converter.BaseInfo{})
+	// 未显式指定的参数使用外部配置
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	renameRules := cfg.RenameRules
+	if setFlags["rename"] {
+		renameRules = []string{*rename}
+	}
+	nameOptions := processor.NameOptions{
+		AddEmoji:       cfg.AddEmoji,
+		RemoveOldEmoji: cfg.RemoveOldEmoji,
+	}
+	if setFlags["emoji"] {
+		nameOptions.AddEmoji = *addEmoji
+	}
+	if setFlags["remove-emoji"] {
+		nameOptions.RemoveOldEmoji = *removeEmoji
+	}
+	if nameOptions.RenameRules, err = processor.ParseRenameRules(renameRules...); err != nil {
+		log.Fatalf("重命名规则无效: %v", err)
+	}
+	if nameOptions.EmojiRules, err = processor.ParseEmojiRules(cfg.EmojiRules); err != nil {
+		log.Fatalf("emoji 规则无效: %v", err)
+	}
+	processor.ProcessNames(nodes, nameOptions)
+	if *infoNode {
+		nodes = processor.PrependInfoNode(nodes, info)
+	}
+
+	conv, err := converter.NewConverter(*targetFormat, &converter.BaseInfo{})
+	if err != nil {
+		log.Fatalf("创建转换器失败: %v", err)
+	}
+	if clash, ok := conv.(interface{ SetRuleProviders(bool) }); ok {
+		clash.SetRuleProviders(!*expandRules)
+	}
+	if surge, ok := conv.(*converter.SurgeConverter); ok {
+		if err := surge.SetVersion(*surgeVersion); err != nil {
+			log.Fatalf("创建转换器失败: %v", err)
+		}
+	}
+	if singBox, ok := conv.(*converter.SingBoxConverter); ok && *templateFile != "" {
+		template, err := os.ReadFile(*templateFile)
+		if err != nil {
+			log.Fatalf("读取模板失败: %v", err)
+		}
+		if err := singBox.SetTemplate(template); err != nil {
+			log.Fatalf("读取模板失败: %v", err)
+		}
+	}
 
 	result, err := conv.Convert(nodes, cfg)
 	if err != nil {
 		log.Fatalf("转换失败: %v", err)
 	}
+	log.Printf("下载缓存: %s", contentFetcher.Cache().Stats())
 
 	// 输出结果
 	if *outputFile != "" {
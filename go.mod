@@ -3,6 +3,7 @@ module goconverter
 go 1.23.2
 
 require (
+	github.com/andybalholm/brotli v1.2.6
 	github.com/goccy/go-yaml v1.14.2
 	gopkg.in/ini.v1 v1.67.0
 )
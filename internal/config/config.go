@@ -2,6 +2,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"goconverter/internal/fetcher"
 	"path"
@@ -17,6 +18,8 @@ type ClashRule struct {
 	Pararm    string
 	Strategy  string
 	NoResolve string
+	Source    string // 来源规则集地址，内联规则为空
+	Provider  string // 来源规则集对应的 RuleProvider 名称
 }
 
 // ProxyGroup 表示一个代理组配置
@@ -27,14 +30,23 @@ type ProxyGroup struct {
 	URL       string // 用于 url-test
 	Interval  int    // 用于 url-test
 	Tolerance int    // 用于 url-test
+	Timeout   int    // 测速超时(秒)
 }
 
 // ClashConfig 存储完整的配置
 type ClashConfig struct {
 	RuleSets        []ClashRule
+	RuleProviders   []RuleProvider
 	ProxyGroups     []ProxyGroup
 	EnableGenerator bool
 	OverwriteRules  bool
+	RulesetStatus   []RulesetStatus // 每个远程或本地规则集的加载结果，按配置顺序
+	IncludeRemarks  []string        // 节点备注需匹配的正则
+	ExcludeRemarks  []string        // 节点备注需排除的正则
+	RenameRules     []string        // 节点重命名规则 regex@replacement
+	EmojiRules      []string        // 节点旗帜规则 regex,emoji
+	AddEmoji        bool            // 是否为节点添加旗帜
+	RemoveOldEmoji  bool            // 是否先去掉节点已有的 emoji
 }
 
 func parseProxyGroup(value string) ProxyGroup {
@@ -74,11 +86,17 @@ func parseProxyGroup(value string) ProxyGroup {
 						group.Interval = num
 					}
 				}
+				if len(testOptions) > 1 {
+					if num, err := strconv.Atoi(testOptions[1]); err == nil {
+						group.Timeout = num
+					}
+				}
 				if len(testOptions) > 2 {
 					if num, err := strconv.Atoi(testOptions[2]); err == nil {
 						group.Tolerance = num
 					}
 				}
+				continue
 			}
 
 			group.Proxies = append(group.Proxies, option)
@@ -89,6 +107,29 @@ func parseProxyGroup(value string) ProxyGroup {
 }
 
 func ParseConfig(content []byte) (*ClashConfig, error) {
+	return ParseConfigWithFetcher(content, fetcher.NewFetcher())
+}
+
+// ParseConfigWithFetcher 解析配置，远程规则集通过 contentFetcher 下载以便复用缓存
+func ParseConfigWithFetcher(content []byte, contentFetcher *fetcher.Fetcher) (*ClashConfig, error) {
+	return parseConfig(context.Background(), content, contentFetcher, "", DefaultRulesetWorkers)
+}
+
+// LoadConfig 下载并解析配置，配置中相对路径的规则集以 configURL 为基准
+func LoadConfig(contentFetcher *fetcher.Fetcher, configURL string) (*ClashConfig, error) {
+	return LoadConfigContext(context.Background(), contentFetcher, configURL, DefaultRulesetWorkers)
+}
+
+// LoadConfigContext 同 LoadConfig，规则集最多由 workers 个协程并发下载，ctx 取消时停止下载
+func LoadConfigContext(ctx context.Context, contentFetcher *fetcher.Fetcher, configURL string, workers int) (*ClashConfig, error) {
+	content, err := contentFetcher.FetchContext(ctx, configURL)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %v", err)
+	}
+	return parseConfig(ctx, content, contentFetcher, configURL, workers)
+}
+
+func parseConfig(ctx context.Context, content []byte, contentFetcher *fetcher.Fetcher, baseURL string, workers int) (*ClashConfig, error) {
 	cfg, err := ini.LoadSources(ini.LoadOptions{
 		AllowShadows:             true,
 		Insensitive:              true,
@@ -102,9 +143,11 @@ func ParseConfig(content []byte) (*ClashConfig, error) {
 	section := cfg.Section("custom")
 	config := &ClashConfig{}
 
-	contentFetcher := fetcher.NewFetcher()
+	providerNames := make(map[string]bool)
 
-	// 解析 ruleset
+	// 解析 ruleset，远程规则集并发下载后按配置顺序合并
+	items := make([]rulesetItem, 0)
+	jobs := make([]*rulesetJob, 0)
 	rulesetKeys := section.Key("ruleset").ValueWithShadows()
 	for _, ruleStr := range rulesetKeys {
 		parts := strings.SplitN(ruleStr, ",", 2)
@@ -127,43 +170,35 @@ func ParseConfig(content []byte) (*ClashConfig, error) {
 					Pararm:    ruleParm,
 					NoResolve: "",
 				}
-				config.RuleSets = append(config.RuleSets, rule)
+				items = append(items, rulesetItem{rule: &rule})
 			} else {
-				contentUrl := parts[1]
-				// convert to online rule
-				if strings.HasPrefix(contentUrl, "rules/ACL4SSR/Clash/") {
-					contentUrl = "https://raw.githubusercontent.com/ACL4SSR/ACL4SSR/refs/heads/master/Clash" +
-						strings.SplitAfterN(parts[1], "/Clash", 2)[1]
-				}
-				listContent, err := contentFetcher.Fetch(contentUrl)
-				if err != nil {
-					continue
-				}
-				for _, line := range strings.Split(string(listContent), "\n") {
-					if strings.HasPrefix(line, "#") || line == "" {
-						continue
-					}
-					lineParam := strings.SplitN(line, ",", 3)
-					param := ""
-					if len(lineParam) > 1 {
-						param = lineParam[1]
-					}
-					noReslove := ""
-					if len(lineParam) > 2 {
-						noReslove = lineParam[2]
-					}
-					rule := ClashRule{
-						Strategy:  parts[0],
-						Type:      lineParam[0],
-						Pararm:    param,
-						NoResolve: noReslove,
-					}
-					config.RuleSets = append(config.RuleSets, rule)
-				}
+				job := prepareRuleset(baseURL, parts[0], parts[1], providerNames)
+				items = append(items, rulesetItem{job: job})
+				jobs = append(jobs, job)
 			}
 		}
 	}
 
+	fetchRulesets(ctx, contentFetcher, jobs, workers)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("加载规则集失败: %v", err)
+	}
+	for _, item := range items {
+		if item.rule != nil {
+			config.RuleSets = append(config.RuleSets, *item.rule)
+			continue
+		}
+		job := item.job
+		config.RulesetStatus = append(config.RulesetStatus, job.status)
+		if job.status.Err != nil {
+			continue
+		}
+		if job.provider != nil {
+			config.RuleProviders = append(config.RuleProviders, *job.provider)
+		}
+		config.RuleSets = append(config.RuleSets, job.rules...)
+	}
+
 	// 解析 custom_proxy_group
 	groupKeys := section.Key("custom_proxy_group").ValueWithShadows()
 	for _, groupStr := range groupKeys {
@@ -174,6 +209,12 @@ func ParseConfig(content []byte) (*ClashConfig, error) {
 	// 解析其他设置
 	config.EnableGenerator = section.Key("enable_rule_generator").MustBool(false)
 	config.OverwriteRules = section.Key("overwrite_original_rules").MustBool(false)
+	config.IncludeRemarks = nonEmptyValues(section.Key("include_remarks").ValueWithShadows())
+	config.ExcludeRemarks = nonEmptyValues(section.Key("exclude_remarks").ValueWithShadows())
+	config.RenameRules = nonEmptyValues(append(section.Key("rename_node").ValueWithShadows(), section.Key("rename").ValueWithShadows()...))
+	config.EmojiRules = nonEmptyValues(section.Key("emoji").ValueWithShadows())
+	config.AddEmoji = section.Key("add_emoji").MustBool(false)
+	config.RemoveOldEmoji = section.Key("remove_old_emoji").MustBool(false)
 
 	return config, nil
 }
@@ -189,3 +230,14 @@ func getLastTwoPaths(urlPath string) string {
 
 	return "/" + strings.Join(parts[len(parts)-2:], "/")
 }
+
+// nonEmptyValues 去掉空白的配置项
+func nonEmptyValues(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -2,18 +2,21 @@
 package config
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"goconverter/internal/fetcher"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseConfig(t *testing.T) {
 
-	testConfig, err := os.ReadFile("D:\\Projects\\goconverter\\test\\data\\ACL4SSR.ini")
-	if err != nil {
-		t.Fatalf("读取配置文件失败: %v", err)
-	}
-
-	cfg, err := ParseConfig(testConfig)
+	// 规则集使用 test/data/rules 下的本地副本，不需要联网
+	cfg, err := LoadConfig(fetcher.NewFetcher(), "../../test/data/ACL4SSR.ini")
 	if err != nil {
 		t.Fatalf("解析配置失败: %v", err)
 	}
@@ -21,6 +24,143 @@ func TestParseConfig(t *testing.T) {
 	t.Logf("Rulesets size: %d", len(cfg.RuleSets))
 	t.Logf("ProxyGroups size: %d", len(cfg.ProxyGroups))
 
+	if len(cfg.RuleSets) != 35 {
+		t.Errorf("Rulesets size = %d, want 35", len(cfg.RuleSets))
+	}
+	if len(cfg.ProxyGroups) != 10 {
+		t.Errorf("ProxyGroups size = %d, want 10", len(cfg.ProxyGroups))
+	}
+	// 本地规则集客户端无法下载，只展开为内联规则
+	if len(cfg.RuleProviders) != 0 {
+		t.Errorf("RuleProviders size = %d, want 0", len(cfg.RuleProviders))
+	}
+	if rule := cfg.RuleSets[0]; rule.Type != "DOMAIN-SUFFIX" || rule.Pararm != "local" || rule.Strategy != "🎯 全球直连" {
+		t.Errorf("first rule = %+v", rule)
+	}
+
 	// fmt.Printf("%+v\n", cfg)
 
 }
+
+func TestLoadRulesetSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		source   string
+		want     string
+		provider bool
+	}{
+		{"acl4ssr without base", "", "clash-domain:rules/ACL4SSR/Clash/BanAD.list", "https://raw.githubusercontent.com/ACL4SSR/ACL4SSR/refs/heads/master/Clash/BanAD.list", true},
+		{"relative to remote config", "https://example.com/config/my.ini", "clash-domain:../rules/proxy.list", "https://example.com/rules/proxy.list", true},
+		{"data uri", "https://example.com/config/my.ini", "data:,DOMAIN-SUFFIX%2Cexample.com", "data:,DOMAIN-SUFFIX%2Cexample.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := prepareRuleset(tt.baseURL, "DIRECT", tt.source, map[string]bool{})
+			fetchRulesets(context.Background(), fetcher.NewFetcher(fetcher.WithLocalFiles(false)), []*rulesetJob{job}, 1)
+			if job.status.Err != nil {
+				t.Fatalf("fetchRulesets() error = %v", job.status.Err)
+			}
+			if job.rules[0].Source != tt.want {
+				t.Errorf("Source = %q, want %q", job.rules[0].Source, tt.want)
+			}
+			if (job.provider != nil) != tt.provider {
+				t.Errorf("provider = %+v", job.provider)
+			}
+		})
+	}
+
+	job := prepareRuleset("", "DIRECT", "/etc/passwd", map[string]bool{})
+	fetchRulesets(context.Background(), fetcher.NewFetcher(fetcher.WithLocalFiles(false)), []*rulesetJob{job}, 1)
+	if job.status.Err == nil {
+		t.Error("fetchRulesets() should not read local files when disabled")
+	}
+}
+
+func TestLoadRulesetsConcurrently(t *testing.T) {
+	// 越靠前的规则集响应越慢，合并结果仍需保持配置顺序
+	delays := map[string]time.Duration{"/a.list": 60 * time.Millisecond, "/b.list": 30 * time.Millisecond, "/c.list": 0}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, ok := delays[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprintf(w, "DOMAIN-SUFFIX,%s.com\n", strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".list"))
+	}))
+	defer server.Close()
+
+	content := fmt.Sprintf(`[custom]
+ruleset=A,%[1]s/a.list
+ruleset=B,%[1]s/b.list
+ruleset=MISSING,%[1]s/missing.list
+ruleset=C,%[1]s/c.list
+ruleset=FINAL,[]FINAL
+`, server.URL)
+	contentFetcher := fetcher.NewFetcher(fetcher.WithRetry(0, 0))
+
+	cfg, err := parseConfig(context.Background(), []byte(content), contentFetcher, "", 3)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	var got []string
+	for _, rule := range cfg.RuleSets {
+		got = append(got, rule.Strategy)
+	}
+	if want := "A,B,C,FINAL"; strings.Join(got, ",") != want {
+		t.Errorf("rule order = %v, want %s", got, want)
+	}
+	if len(cfg.RulesetStatus) != 4 {
+		t.Fatalf("RulesetStatus = %+v", cfg.RulesetStatus)
+	}
+	if status := cfg.RulesetStatus[2]; status.Strategy != "MISSING" || status.Err == nil {
+		t.Errorf("missing ruleset status = %+v", status)
+	}
+	if status := cfg.RulesetStatus[0]; status.Err != nil || status.Rules != 1 {
+		t.Errorf("ruleset A status = %+v", status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := parseConfig(ctx, []byte(content), contentFetcher, "", 1); err == nil {
+		t.Error("parseConfig() should fail when context is canceled")
+	}
+}
+
+func TestRulesetInlineOrProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "DOMAIN-SUFFIX,example.com")
+	}))
+	defer server.Close()
+
+	content := fmt.Sprintf(`[custom]
+ruleset=DEFAULT,%[1]s/default.list
+ruleset=INLINE,inline:%[1]s/inline.list
+ruleset=PROVIDER,clash-domain:%[1]s/provider.list
+`, server.URL)
+	cfg, err := parseConfig(context.Background(), []byte(content), fetcher.NewFetcher(), "", 1)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+
+	providers := make(map[string]string)
+	for _, rule := range cfg.RuleSets {
+		providers[rule.Strategy] = rule.Type + "|" + rule.Provider
+	}
+	want := map[string]string{
+		"DEFAULT":  "DOMAIN-SUFFIX|default", // 跟随全局 expand
+		"INLINE":   "DOMAIN-SUFFIX|",        // 总是展开
+		"PROVIDER": "RULE-SET|provider",     // 总是引用 rule-provider
+	}
+	if !reflect.DeepEqual(providers, want) {
+		t.Errorf("rules = %v, want %v", providers, want)
+	}
+	if len(cfg.RuleProviders) != 2 {
+		t.Errorf("RuleProviders = %+v", cfg.RuleProviders)
+	}
+}
@@ -0,0 +1,173 @@
+// internal/config/loader.go
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goconverter/internal/fetcher"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRulesetWorkers 默认同时下载的规则集数量
+const DefaultRulesetWorkers = 8
+
+// RulesetStatus 单个规则集的加载结果
+type RulesetStatus struct {
+	Strategy string
+	Source   string        // 配置中的写法
+	URL      string        // 解析后的地址
+	Rules    int           // 展开后的规则数
+	Duration time.Duration // 下载与解析耗时
+	Err      error         // 加载失败的原因，失败的规则集会被跳过
+}
+
+// rulesetItem 配置中的一条 ruleset，内联规则或待加载的规则集
+type rulesetItem struct {
+	rule *ClashRule
+	job  *rulesetJob
+}
+
+// rulesetJob 待加载的规则集
+type rulesetJob struct {
+	url         string
+	strategy    string
+	provider    *RuleProvider // 本地规则集为 nil
+	placeholder bool          // 只生成一条 RULE-SET 规则，不需要下载
+	rules       []ClashRule
+	status      RulesetStatus
+}
+
+// prepareRuleset 解析规则集地址并分配 provider 名称
+//
+// provider 名称按出现顺序去重，需要在并发下载前按配置顺序调用。
+// 相对路径以配置地址 baseURL 为基准；本地规则集客户端无法下载，与 inline: 规则集一样总是展开且不生成 RuleProvider。
+func prepareRuleset(baseURL, strategy, source string, providerNames map[string]bool) *rulesetJob {
+	contentUrl, behavior, interval, inline := parseRulesetSource(source)
+	// 远程配置或未指定配置地址时，ACL4SSR 的相对路径转为 GitHub 地址
+	if strings.HasPrefix(contentUrl, "rules/ACL4SSR/Clash/") && (baseURL == "" || fetcher.IsRemote(baseURL)) {
+		contentUrl = "https://raw.githubusercontent.com/ACL4SSR/ACL4SSR/refs/heads/master/Clash" +
+			strings.SplitAfterN(contentUrl, "/Clash", 2)[1]
+	}
+	contentUrl = fetcher.ResolveReference(baseURL, contentUrl)
+
+	job := &rulesetJob{
+		url:      contentUrl,
+		strategy: strategy,
+		status: RulesetStatus{
+			Strategy: strategy,
+			Source:   source,
+			URL:      contentUrl,
+		},
+	}
+	if inline || !fetcher.IsRemote(contentUrl) {
+		if rulesetFormat(contentUrl) == FormatMRS {
+			job.status.Err = errors.New("mrs ruleset cannot be expanded inline")
+		}
+		return job
+	}
+
+	provider := &RuleProvider{
+		Name:     providerName(contentUrl, providerNames),
+		Behavior: behavior,
+		Format:   rulesetFormat(contentUrl),
+		URL:      contentUrl,
+		Interval: interval,
+	}
+	ext := provider.Format
+	if ext == FormatText {
+		ext = "txt"
+	}
+	provider.Path = fmt.Sprintf("./ruleset/%s.%s", provider.Name, ext)
+	job.provider = provider
+
+	// 显式指定的规则集以及无法展开的 mrs 直接引用
+	if behavior != "" || provider.Format == FormatMRS {
+		if provider.Behavior == "" {
+			provider.Behavior = BehaviorDomain
+		}
+		job.placeholder = true
+		job.rules = []ClashRule{{
+			Type:     "RULE-SET",
+			Pararm:   provider.Name,
+			Strategy: strategy,
+			Source:   contentUrl,
+			Provider: provider.Name,
+		}}
+	}
+	return job
+}
+
+// fetchRulesets 最多使用 workers 个协程下载规则集，结果写回各自的 job
+func fetchRulesets(ctx context.Context, contentFetcher *fetcher.Fetcher, jobs []*rulesetJob, workers int) {
+	if workers <= 0 {
+		workers = DefaultRulesetWorkers
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for _, job := range jobs {
+		if job.status.Err != nil || job.placeholder {
+			job.status.Rules = len(job.rules)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			job.status.Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(job *rulesetJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fetchRuleset(ctx, contentFetcher, job)
+		}(job)
+	}
+	wg.Wait()
+
+	for _, job := range jobs {
+		if job.status.Err != nil {
+			log.Printf("跳过规则集 %s: %v", job.status.Source, job.status.Err)
+		} else {
+			log.Printf("规则集 %s: %d 条规则, 耗时 %v", job.status.URL, job.status.Rules, job.status.Duration)
+		}
+	}
+}
+
+// fetchRuleset 下载并展开单个规则集
+func fetchRuleset(ctx context.Context, contentFetcher *fetcher.Fetcher, job *rulesetJob) {
+	start := time.Now()
+	defer func() {
+		job.status.Duration = time.Since(start)
+	}()
+
+	listContent, err := contentFetcher.FetchContext(ctx, job.url)
+	if err != nil {
+		job.status.Err = err
+		return
+	}
+	format := rulesetFormat(job.url)
+	entries, err := rulesetEntries(listContent, format)
+	if err != nil {
+		job.status.Err = err
+		return
+	}
+
+	rules := expandRuleset(entries, job.strategy)
+	for i := range rules {
+		rules[i].Source = job.url
+	}
+	if job.provider != nil {
+		job.provider.Behavior = inferBehavior(entries)
+		for i := range rules {
+			rules[i].Provider = job.provider.Name
+		}
+	}
+	job.rules = rules
+	job.status.Rules = len(rules)
+}
@@ -0,0 +1,202 @@
+// internal/config/ruleset.go
+package config
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// 规则集行为，对应 Clash rule-providers 的 behavior
+const (
+	BehaviorClassical = "classical"
+	BehaviorDomain    = "domain"
+	BehaviorIPCIDR    = "ipcidr"
+)
+
+// 规则集格式，对应 Clash rule-providers 的 format
+const (
+	FormatText = "text"
+	FormatYAML = "yaml"
+	FormatMRS  = "mrs"
+)
+
+// 默认的规则集更新间隔(秒)
+const defaultRulesetInterval = 86400
+
+// RuleProvider 表示一个远程规则集，对应 Clash 的 rule-providers
+type RuleProvider struct {
+	Name     string
+	Behavior string // classical/domain/ipcidr
+	Format   string // text/yaml/mrs
+	URL      string
+	Path     string
+	Interval int
+}
+
+// rulesetPrefixes subconverter 的规则集前缀，显式指定以 rule-provider 方式引用
+var rulesetPrefixes = map[string]string{
+	"clash-domain:":  BehaviorDomain,
+	"clash-ipcidr:":  BehaviorIPCIDR,
+	"clash-classic:": BehaviorClassical,
+}
+
+// rulesetInlinePrefix 显式要求展开为内联规则，不受 expand=false 影响
+const rulesetInlinePrefix = "inline:"
+
+// parseRulesetSource 拆分 ruleset 的地址部分：[clash-xxx:|inline:]URL[,interval]
+//
+// behavior 不为空表示配置显式要求使用 rule-provider，inline 表示显式要求展开
+func parseRulesetSource(source string) (url string, behavior string, interval int, inline bool) {
+	interval = defaultRulesetInterval
+	if i := strings.LastIndex(source, ","); i >= 0 {
+		if num, err := strconv.Atoi(strings.TrimSpace(source[i+1:])); err == nil {
+			source = source[:i]
+			interval = num
+		}
+	}
+
+	if after, found := strings.CutPrefix(source, rulesetInlinePrefix); found {
+		return strings.TrimSpace(after), "", interval, true
+	}
+	for prefix, b := range rulesetPrefixes {
+		if after, found := strings.CutPrefix(source, prefix); found {
+			return strings.TrimSpace(after), b, interval, false
+		}
+	}
+	return strings.TrimSpace(source), "", interval, false
+}
+
+// rulesetFormat 根据地址后缀判断规则集格式
+func rulesetFormat(url string) string {
+	switch strings.ToLower(path.Ext(strings.SplitN(url, "?", 2)[0])) {
+	case ".mrs":
+		return FormatMRS
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatText
+}
+
+// providerName 根据地址生成不重复的 rule-provider 名称
+func providerName(url string, used map[string]bool) string {
+	base := path.Base(strings.SplitN(url, "?", 2)[0])
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if base == "" || base == "." || base == "/" {
+		base = "ruleset"
+	}
+
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// rulesetEntries 读取规则集内容，text 按行、yaml 读取 payload
+func rulesetEntries(content []byte, format string) ([]string, error) {
+	if format == FormatYAML {
+		payload := struct {
+			Payload []string `yaml:"payload"`
+		}{}
+		if err := yaml.Unmarshal(content, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ruleset payload: %v", err)
+		}
+		return payload.Payload, nil
+	}
+
+	entries := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, nil
+}
+
+// inferBehavior 根据规则集内容推断 behavior
+//
+// 带规则类型(逗号分隔)的是 classical，只有 IP/CIDR 的是 ipcidr，只有域名的是 domain
+func inferBehavior(entries []string) string {
+	hasDomain, hasIP := false, false
+	for _, entry := range entries {
+		if strings.Contains(entry, ",") {
+			return BehaviorClassical
+		}
+		if isIPEntry(entry) {
+			hasIP = true
+		} else {
+			hasDomain = true
+		}
+	}
+	if hasIP && !hasDomain {
+		return BehaviorIPCIDR
+	}
+	if hasDomain && !hasIP {
+		return BehaviorDomain
+	}
+	return BehaviorClassical
+}
+
+func isIPEntry(entry string) bool {
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return true
+	}
+	return net.ParseIP(entry) != nil
+}
+
+// expandRuleset 将规则集展开为内联规则
+func expandRuleset(entries []string, strategy string) []ClashRule {
+	rules := make([]ClashRule, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry, ",") {
+			// DOMAIN-SUFFIX,google.com[,no-resolve]
+			lineParam := strings.SplitN(entry, ",", 3)
+			noResolve := ""
+			if len(lineParam) > 2 {
+				noResolve = lineParam[2]
+			}
+			rules = append(rules, ClashRule{
+				Type:      lineParam[0],
+				Pararm:    lineParam[1],
+				Strategy:  strategy,
+				NoResolve: noResolve,
+			})
+			continue
+		}
+
+		rule := ClashRule{Strategy: strategy}
+		switch {
+		case isIPEntry(entry):
+			if !strings.Contains(entry, "/") {
+				if strings.Contains(entry, ":") {
+					entry += "/128"
+				} else {
+					entry += "/32"
+				}
+			}
+			rule.Type = "IP-CIDR"
+			if strings.Contains(entry, ":") {
+				rule.Type = "IP-CIDR6"
+			}
+			rule.Pararm = entry
+			rule.NoResolve = "no-resolve"
+		case strings.HasPrefix(entry, "+.") || strings.HasPrefix(entry, "."):
+			rule.Type = "DOMAIN-SUFFIX"
+			rule.Pararm = strings.TrimLeft(entry, "+.")
+		default:
+			// 其余视为完整域名
+			rule.Type = "DOMAIN"
+			rule.Pararm = entry
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
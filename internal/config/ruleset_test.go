@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRulesetSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		url      string
+		behavior string
+		interval int
+		inline   bool
+	}{
+		{"rules/ACL4SSR/Clash/BanAD.list", "rules/ACL4SSR/Clash/BanAD.list", "", 86400, false},
+		{"https://example.com/ad.list,3600", "https://example.com/ad.list", "", 3600, false},
+		{"clash-domain:https://example.com/cn.txt", "https://example.com/cn.txt", BehaviorDomain, 86400, false},
+		{"clash-ipcidr:https://example.com/cn.mrs,600", "https://example.com/cn.mrs", BehaviorIPCIDR, 600, false},
+		{"inline:https://example.com/ad.list,3600", "https://example.com/ad.list", "", 3600, true},
+	}
+
+	for _, tt := range tests {
+		url, behavior, interval, inline := parseRulesetSource(tt.source)
+		if url != tt.url || behavior != tt.behavior || interval != tt.interval || inline != tt.inline {
+			t.Errorf("parseRulesetSource(%q) = %q, %q, %d, %v", tt.source, url, behavior, interval, inline)
+		}
+	}
+}
+
+func TestInferBehavior(t *testing.T) {
+	tests := []struct {
+		entries []string
+		want    string
+	}{
+		{[]string{"DOMAIN-SUFFIX,google.com", "IP-CIDR,1.1.1.1/32,no-resolve"}, BehaviorClassical},
+		{[]string{"+.google.com", "www.example.com"}, BehaviorDomain},
+		{[]string{"1.0.0.0/24", "2001:db8::/32", "8.8.8.8"}, BehaviorIPCIDR},
+		{[]string{"+.google.com", "1.0.0.0/24"}, BehaviorClassical},
+	}
+
+	for _, tt := range tests {
+		if got := inferBehavior(tt.entries); got != tt.want {
+			t.Errorf("inferBehavior(%v) = %s, want %s", tt.entries, got, tt.want)
+		}
+	}
+}
+
+func TestExpandRuleset(t *testing.T) {
+	got := expandRuleset([]string{"DOMAIN-KEYWORD,google", "+.example.com", "www.example.org", "1.0.0.0/24", "2001:db8::1"}, "Proxy")
+	want := []ClashRule{
+		{Type: "DOMAIN-KEYWORD", Pararm: "google", Strategy: "Proxy"},
+		{Type: "DOMAIN-SUFFIX", Pararm: "example.com", Strategy: "Proxy"},
+		{Type: "DOMAIN", Pararm: "www.example.org", Strategy: "Proxy"},
+		{Type: "IP-CIDR", Pararm: "1.0.0.0/24", Strategy: "Proxy", NoResolve: "no-resolve"},
+		{Type: "IP-CIDR6", Pararm: "2001:db8::1/128", Strategy: "Proxy", NoResolve: "no-resolve"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandRuleset() = %+v, want %+v", got, want)
+	}
+}
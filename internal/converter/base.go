@@ -2,15 +2,23 @@
 package converter
 
 import (
+	"fmt"
+	"goconverter/internal/config"
 	"goconverter/internal/subscription/model"
 )
 
 // Converter 定义转换器接口
 type Converter interface {
 	// Convert 将节点列表转换为目标格式的配置字符串
-	Convert(nodes []*model.Node) (string, error)
+	Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error)
+	// ConvertList 只输出节点列表，用于 list=true
+	ConvertList(nodes []*model.Node) (string, error)
 	// ConvertNode 转换单个节点配置
 	ConvertNode(node *model.Node) (interface{}, error)
+	// ContentType 输出内容的 MIME 类型
+	ContentType() string
+	// FileName 默认下载文件名
+	FileName() string
 }
 
 // BaseInfo 基础转换信息
@@ -21,3 +29,37 @@ type BaseInfo struct {
 	Tags        []string          // 标签
 	Rules       map[string]string // 规则配置
 }
+
+// NewConverter 根据目标格式创建转换器，target 与 subconverter 保持一致
+func NewConverter(target string, info *BaseInfo) (Converter, error) {
+	switch target {
+	case "clash":
+		return NewClashConverter(info), nil
+	case "surge":
+		return NewSurgeConverter(*info), nil
+	case "loon":
+		return NewLoonConverter(info), nil
+	case "stash":
+		return NewStashConverter(info), nil
+	case "quanx":
+		return NewQuantumultXConverter(info), nil
+	case "mixed":
+		return NewLinkConverter(info, target), nil
+	case "ss":
+		return NewLinkConverter(info, target, model.TypeSS), nil
+	case "ssr":
+		return NewLinkConverter(info, target, model.TypeSSR), nil
+	case "v2ray":
+		return NewLinkConverter(info, target, model.TypeVmess), nil
+	case "trojan":
+		return NewLinkConverter(info, target, model.TypeTrojan), nil
+	case "vless":
+		return NewLinkConverter(info, target, model.TypeVless), nil
+	case "sip008":
+		return NewSIP008Converter(info), nil
+	case "singbox":
+		return NewSingBoxConverter(info), nil
+	default:
+		return nil, fmt.Errorf("unsupported target: %s", target)
+	}
+}
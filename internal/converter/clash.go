@@ -12,7 +12,8 @@ import (
 )
 
 type ClashConverter struct {
-	info *BaseInfo
+	info          *BaseInfo
+	ruleProviders bool // 远程规则集输出为 rule-providers，而不是展开为内联规则
 }
 
 func NewClashConverter(info *BaseInfo) *ClashConverter {
@@ -21,6 +22,11 @@ func NewClashConverter(info *BaseInfo) *ClashConverter {
 	}
 }
 
+// SetRuleProviders 设置远程规则集是否以 rule-providers 方式输出，对应 subconverter 的 expand=false
+func (c *ClashConverter) SetRuleProviders(enable bool) {
+	c.ruleProviders = enable
+}
+
 type ClashConfig struct {
 	Port               int                      `yaml:"port"`
 	SocksPort          int                      `yaml:"socks-port"`
@@ -32,6 +38,7 @@ type ClashConfig struct {
 	DNS                map[string]interface{}   `yaml:"dns"`
 	Proxies            []map[string]interface{} `yaml:"proxies"`
 	ProxyGroups        []*ProxyGroup            `yaml:"proxy-groups"`
+	RuleProviders      map[string]*RuleProvider `yaml:"rule-providers,omitempty"`
 	Rules              []string                 `yaml:"rules"`
 }
 
@@ -71,7 +78,33 @@ type ProxyGroup struct {
 	Proxies   []string `yaml:"proxies"`
 }
 
+// RuleProvider 定义 rule-providers 中的远程规则集
+type RuleProvider struct {
+	Type     string `yaml:"type"`             // http
+	Behavior string `yaml:"behavior"`         // classical/domain/ipcidr
+	Format   string `yaml:"format,omitempty"` // text/yaml/mrs
+	URL      string `yaml:"url"`
+	Path     string `yaml:"path"`
+	Interval int    `yaml:"interval"`
+}
+
 func (c *ClashConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	config, err := c.buildConfig(nodes, clashConfig)
+	if err != nil {
+		return "", err
+	}
+
+	// 转换为YAML
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal clash config: %v", err)
+	}
+
+	return string(data), nil
+}
+
+// buildConfig 生成完整的 Clash 配置，Stash 在此基础上调整
+func (c *ClashConverter) buildConfig(nodes []*model.Node, clashConfig *config.ClashConfig) (*ClashConfig, error) {
 	config := &ClashConfig{
 		Port:               7890,
 		SocksPort:          7891,
@@ -90,51 +123,55 @@ func (c *ClashConverter) Convert(nodes []*model.Node, clashConfig *config.ClashC
 	}
 
 	// 转换所有节点
-	nodeNames := make([]string, 0, len(nodes))
+	converted := make([]*model.Node, 0, len(nodes))
 	for _, node := range nodes {
 		proxy, err := c.ConvertNode(node)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if proxyMap, ok := proxy.(map[string]interface{}); ok {
 			config.Proxies = append(config.Proxies, proxyMap)
-			nodeNames = append(nodeNames, node.Name)
+			converted = append(converted, node)
 		}
 	}
 
 	// 添加代理组
 	for _, configProxyGroup := range clashConfig.ProxyGroups {
+		proxies, err := groupProxies(configProxyGroup, converted)
+		if err != nil {
+			return nil, err
+		}
 		proxyGroup := &ProxyGroup{
 			Name:      configProxyGroup.Name,
 			Type:      configProxyGroup.Type,
 			URL:       configProxyGroup.URL,
 			Interval:  configProxyGroup.Interval,
 			Tolerance: configProxyGroup.Tolerance,
-			Proxies:   make([]string, 0),
-		}
-
-		for _, name := range configProxyGroup.Proxies {
-			if after, found := strings.CutPrefix(name, "[]"); found {
-				proxyGroup.Proxies = append(proxyGroup.Proxies, after)
-			} else {
-				for _, nodeName := range nodeNames {
-					proxyGroup.Proxies = append(proxyGroup.Proxies, nodeName)
-				}
-			}
+			Proxies:   proxies,
 		}
 
 		config.ProxyGroups = append(config.ProxyGroups, proxyGroup)
 	}
 
 	// 添加规则
-	for _, rule := range c.getRules(clashConfig) {
-		config.Rules = append(config.Rules, rule)
+	rules, providers := c.getRules(clashConfig)
+	config.Rules = append(config.Rules, rules...)
+	if len(providers) > 0 {
+		config.RuleProviders = providers
 	}
 
-	// 转换为YAML
-	data, err := yaml.Marshal(config)
+	return config, nil
+}
+
+func (c *ClashConverter) ConvertList(nodes []*model.Node) (string, error) {
+	proxies := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		proxies = append(proxies, node.ToClash())
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"proxies": proxies})
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal clash config: %v", err)
+		return "", fmt.Errorf("failed to marshal clash proxies: %v", err)
 	}
 
 	return string(data), nil
@@ -144,9 +181,40 @@ func (c *ClashConverter) ConvertNode(node *model.Node) (interface{}, error) {
 	return node.ToClash(), nil
 }
 
-func (c *ClashConverter) getRules(clashConfig *config.ClashConfig) []string {
+func (c *ClashConverter) ContentType() string {
+	return "text/yaml; charset=utf-8"
+}
+
+func (c *ClashConverter) FileName() string {
+	return "clash.yaml"
+}
+
+func (c *ClashConverter) getRules(clashConfig *config.ClashConfig) ([]string, map[string]*RuleProvider) {
 	rules := make([]string, 0)
+	providers := make(map[string]*RuleProvider)
 	for _, ruleset := range clashConfig.RuleSets {
+		// 来自远程规则集的规则合并为一条 RULE-SET
+		if ruleset.Provider != "" && (c.ruleProviders || ruleset.Type == "RULE-SET") {
+			if _, found := providers[ruleset.Provider]; found {
+				continue
+			}
+			for _, provider := range clashConfig.RuleProviders {
+				if provider.Name == ruleset.Provider {
+					providers[provider.Name] = &RuleProvider{
+						Type:     "http",
+						Behavior: provider.Behavior,
+						Format:   provider.Format,
+						URL:      provider.URL,
+						Path:     provider.Path,
+						Interval: provider.Interval,
+					}
+					break
+				}
+			}
+			rules = append(rules, strings.Join([]string{"RULE-SET", ruleset.Provider, ruleset.Strategy}, ","))
+			continue
+		}
+
 		if ruleset.Type == "FINAL" {
 			ruleset.Type = "MATCH"
 		}
@@ -170,5 +238,5 @@ func (c *ClashConverter) getRules(clashConfig *config.ClashConfig) []string {
 		rules = append(rules, strings.Join(rulesetslice, ","))
 	}
 
-	return rules
+	return rules, providers
 }
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestClashRuleProviders(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeTrojan, Name: "香港 01", Server: "hk.example.com", Port: 443, Password: "pass"},
+	}
+	clashConfig := &config.ClashConfig{
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "ad.com", Strategy: "REJECT", Provider: "BanAD"},
+			{Type: "DOMAIN-KEYWORD", Pararm: "ads", Strategy: "REJECT", Provider: "BanAD"},
+			{Type: "RULE-SET", Pararm: "cn", Strategy: "DIRECT", Provider: "cn"},
+			{Type: "DOMAIN", Pararm: "inline.example.com", Strategy: "DIRECT", Source: "https://example.com/inline.list"},
+			{Type: "MATCH", Strategy: "DIRECT"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "BanAD", Behavior: "classical", Format: "text", URL: "https://example.com/BanAD.list", Path: "./ruleset/BanAD.txt", Interval: 86400},
+			{Name: "cn", Behavior: "domain", Format: "mrs", URL: "https://example.com/cn.mrs", Path: "./ruleset/cn.mrs", Interval: 86400},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		ruleProviders bool
+		want          []string
+		unwanted      []string
+	}{
+		{
+			name:     "inline",
+			want:     []string{"- DOMAIN-SUFFIX,ad.com,REJECT", "- DOMAIN-KEYWORD,ads,REJECT", "- RULE-SET,cn,DIRECT", "behavior: domain"},
+			unwanted: []string{"RULE-SET,BanAD"},
+		},
+		{
+			name:          "providers",
+			ruleProviders: true,
+			want:          []string{"- RULE-SET,BanAD,REJECT", "- RULE-SET,cn,DIRECT", "- DOMAIN,inline.example.com,DIRECT", "- MATCH,DIRECT", "url: https://example.com/BanAD.list", "behavior: classical"},
+			unwanted:      []string{"DOMAIN-SUFFIX,ad.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := NewClashConverter(&BaseInfo{})
+			conv.SetRuleProviders(tt.ruleProviders)
+			result, err := conv.Convert(nodes, clashConfig)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result, want) {
+					t.Errorf("result missing %q:\n%s", want, result)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("result should not contain %q:\n%s", unwanted, result)
+				}
+			}
+		})
+	}
+}
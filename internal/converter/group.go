@@ -0,0 +1,121 @@
+// internal/converter/group.go
+package converter
+
+import (
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"log"
+	"strings"
+)
+
+// groupProxies 计算 custom_proxy_group 的成员
+//
+// []Name 直接引用策略组或 DIRECT/REJECT，其余条目作为正则匹配节点名称，
+// 也可以用 !!GROUP=、!!GROUPID=、!!INSERT=、!!TYPE=、!!PORT= 先按节点属性筛选，
+// 如 !!GROUPID=0!!(港|HK)。没有任何成员时回退为 DIRECT。
+// 目前没有插入节点的来源，!!INSERT= 不匹配任何节点。
+func groupProxies(group config.ProxyGroup, nodes []*model.Node) ([]string, error) {
+	proxies := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			proxies = append(proxies, name)
+		}
+	}
+
+	for _, option := range group.Proxies {
+		if after, found := strings.CutPrefix(option, "[]"); found {
+			add(after)
+			continue
+		}
+
+		matcher, err := parseNodeMatcher(option)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in group %s: %v", option, group.Name, err)
+		}
+		for _, node := range nodes {
+			if matcher(node) {
+				add(node.Name)
+			}
+		}
+	}
+
+	if len(proxies) == 0 {
+		proxies = append(proxies, "DIRECT")
+	}
+
+	return proxies, nil
+}
+
+// parseNodeMatcher 解析单个节点匹配条目
+func parseNodeMatcher(option string) (func(node *model.Node) bool, error) {
+	var attrMatch func(node *model.Node) bool
+	namePattern := option
+
+	if strings.HasPrefix(option, "!!") {
+		key, rest, _ := strings.Cut(option[2:], "=")
+		value, pattern, _ := strings.Cut(rest, "!!")
+		namePattern = pattern
+
+		switch key {
+		case "GROUP":
+			matcher, err := utils.CompileMatcher(value)
+			if err != nil {
+				return nil, err
+			}
+			attrMatch = func(node *model.Node) bool {
+				return matcher.MatchString(node.Group)
+			}
+		case "GROUPID":
+			attrMatch = func(node *model.Node) bool {
+				return utils.MatchRange(value, node.GroupID)
+			}
+		case "INSERT":
+			log.Printf("!!INSERT=%s 没有可匹配的插入节点", value)
+			attrMatch = func(node *model.Node) bool {
+				return false
+			}
+		case "TYPE":
+			matcher, err := utils.CompileMatcher("(?i)^(?:" + value + ")$")
+			if err != nil {
+				return nil, err
+			}
+			attrMatch = func(node *model.Node) bool {
+				return matcher.MatchString(nodeTypeName(node))
+			}
+		case "PORT":
+			attrMatch = func(node *model.Node) bool {
+				return utils.MatchRange(value, node.Port)
+			}
+		default:
+			return nil, fmt.Errorf("unknown matcher !!%s", key)
+		}
+	}
+
+	var nameMatcher *utils.Matcher
+	if namePattern != "" {
+		matcher, err := utils.CompileMatcher(namePattern)
+		if err != nil {
+			return nil, err
+		}
+		nameMatcher = matcher
+	}
+
+	return func(node *model.Node) bool {
+		if attrMatch != nil && !attrMatch(node) {
+			return false
+		}
+		return nameMatcher == nil || nameMatcher.MatchString(node.Name)
+	}, nil
+}
+
+// nodeTypeName 返回 !!TYPE= 使用的类型名，与 subconverter 一致
+func nodeTypeName(node *model.Node) string {
+	if node.Type == model.TypeHTTP && node.TLS {
+		return "HTTPS"
+	}
+	return strings.ToUpper(string(node.Type))
+}
@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestGroupProxies(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeSS, Name: "香港 01", Port: 443, Group: "机场A", GroupID: 0},
+		{Type: model.TypeTrojan, Name: "日本 01", Port: 8443, Group: "机场A", GroupID: 0},
+		{Type: model.TypeVmess, Name: "HK 02", Port: 80, Group: "机场B", GroupID: 1},
+		{Type: model.TypeHTTP, Name: "US 01", Port: 443, TLS: true, Group: "机场B", GroupID: 1},
+	}
+
+	tests := []struct {
+		name    string
+		proxies []string
+		want    []string
+	}{
+		{"literal", []string{"[]自动选择", "[]DIRECT"}, []string{"自动选择", "DIRECT"}},
+		{"regex", []string{"(港|HK)"}, []string{"香港 01", "HK 02"}},
+		{"lookahead", []string{"^(?!.*日本).*"}, []string{"香港 01", "HK 02", "US 01"}},
+		{"group", []string{"!!GROUP=机场B"}, []string{"HK 02", "US 01"}},
+		{"group with regex", []string{"!!GROUP=机场A!!港"}, []string{"香港 01"}},
+		{"groupid", []string{"!!GROUPID=1!!HK"}, []string{"HK 02"}},
+		{"type", []string{"!!TYPE=SS|Trojan"}, []string{"香港 01", "日本 01"}},
+		{"type https", []string{"!!TYPE=HTTPS"}, []string{"US 01"}},
+		{"port", []string{"!!PORT=443"}, []string{"香港 01", "US 01"}},
+		{"dedup", []string{"港", "(港|HK)"}, []string{"香港 01", "HK 02"}},
+		{"empty fallback", []string{"台湾"}, []string{"DIRECT"}},
+		{"insert", []string{"!!INSERT=0"}, []string{"DIRECT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := groupProxies(config.ProxyGroup{Name: tt.name, Proxies: tt.proxies}, nodes)
+			if err != nil {
+				t.Fatalf("groupProxies() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupProxies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupProxiesInvalidMatcher(t *testing.T) {
+	for _, option := range []string{"!!UNKNOWN=1", "!!GROUP=("} {
+		if _, err := groupProxies(config.ProxyGroup{Name: "test", Proxies: []string{option}}, nil); err == nil {
+			t.Errorf("groupProxies(%q) should fail", option)
+		}
+	}
+}
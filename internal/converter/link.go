@@ -0,0 +1,64 @@
+// internal/subscription/converter/link.go
+package converter
+
+import (
+	"encoding/base64"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/subscription/parser"
+	"log"
+	"strings"
+)
+
+// LinkConverter 输出 base64 编码的分享链接订阅，供 v2rayN、Shadowrocket 等客户端使用
+type LinkConverter struct {
+	info  *BaseInfo
+	name  string                  // 目标名称，用于文件名
+	types map[model.NodeType]bool // 只输出这些协议的节点，为空时输出全部
+}
+
+func NewLinkConverter(info *BaseInfo, name string, types ...model.NodeType) *LinkConverter {
+	c := &LinkConverter{
+		info:  info,
+		name:  name,
+		types: make(map[model.NodeType]bool),
+	}
+	for _, t := range types {
+		c.types[t] = true
+	}
+	return c
+}
+
+// Convert 分享链接订阅不包含策略组和规则，忽略外部配置
+func (c *LinkConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	return c.ConvertList(nodes)
+}
+
+func (c *LinkConverter) ConvertList(nodes []*model.Node) (string, error) {
+	links := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if len(c.types) > 0 && !c.types[node.Type] {
+			continue
+		}
+		link, err := c.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		links = append(links, link.(string))
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(links, "\n"))), nil
+}
+
+func (c *LinkConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	return parser.EncodeLink(node)
+}
+
+func (c *LinkConverter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (c *LinkConverter) FileName() string {
+	return c.name + ".txt"
+}
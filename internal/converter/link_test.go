@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"encoding/base64"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestLinkConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeSS, Name: "ss", Server: "ss.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass"},
+		{Type: model.TypeTrojan, Name: "trojan", Server: "trojan.example.com", Port: 443, Password: "pass", TLS: true},
+	}
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"mixed", []string{"ss://YWVzLTEyOC1nY206cGFzcw@ss.example.com:8388#ss", "trojan://pass@trojan.example.com:443?type=tcp#trojan"}},
+		{"ss", []string{"ss://YWVzLTEyOC1nY206cGFzcw@ss.example.com:8388#ss"}},
+		{"v2ray", []string{""}},
+	}
+
+	for _, tt := range tests {
+		conv, err := NewConverter(tt.target, &BaseInfo{})
+		if err != nil {
+			t.Fatalf("NewConverter(%s) error = %v", tt.target, err)
+		}
+		result, err := conv.Convert(nodes, &config.ClashConfig{})
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(result)
+		if err != nil {
+			t.Fatalf("result is not base64: %v", err)
+		}
+		if got := strings.Split(string(decoded), "\n"); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s links = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
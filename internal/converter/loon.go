@@ -0,0 +1,353 @@
+// internal/subscription/converter/loon.go
+package converter
+
+import (
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// loonSupported Loon 支持的节点类型
+var loonSupported = map[model.NodeType]bool{
+	model.TypeSS:        true,
+	model.TypeSSR:       true,
+	model.TypeVmess:     true,
+	model.TypeVless:     true,
+	model.TypeTrojan:    true,
+	model.TypeHysteria2: true,
+	model.TypeWireGuard: true,
+	model.TypeSocks5:    true,
+	model.TypeHTTP:      true,
+}
+
+// loonRuleTypes Clash 规则类型对应的 Loon 规则类型
+var loonRuleTypes = map[string]string{
+	"DOMAIN":         "DOMAIN",
+	"DOMAIN-SUFFIX":  "DOMAIN-SUFFIX",
+	"DOMAIN-KEYWORD": "DOMAIN-KEYWORD",
+	"IP-CIDR":        "IP-CIDR",
+	"IP-CIDR6":       "IP-CIDR6",
+	"GEOIP":          "GEOIP",
+	"DST-PORT":       "DEST-PORT",
+	"USER-AGENT":     "USER-AGENT",
+	"URL-REGEX":      "URL-REGEX",
+}
+
+type LoonConverter struct {
+	info *BaseInfo
+}
+
+func NewLoonConverter(info *BaseInfo) *LoonConverter {
+	return &LoonConverter{
+		info: info,
+	}
+}
+
+func (l *LoonConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	var builder strings.Builder
+
+	// 写入基础配置
+	builder.WriteString("[General]\n")
+	builder.WriteString("skip-proxy = 127.0.0.1,192.168.0.0/16,10.0.0.0/8,172.16.0.0/12,100.64.0.0/10,localhost,*.local\n")
+	builder.WriteString("dns-server = system,223.5.5.5,114.114.114.114\n\n")
+
+	// 写入节点
+	builder.WriteString("[Proxy]\n")
+	converted := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		proxy, err := l.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		builder.WriteString(proxy.(string) + "\n")
+		converted = append(converted, node)
+	}
+
+	// 写入策略组
+	builder.WriteString("\n[Proxy Group]\n")
+	for _, group := range clashConfig.ProxyGroups {
+		line, err := l.getProxyGroup(group, converted)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	// 写入规则
+	remote, rules := l.getRules(clashConfig)
+	builder.WriteString("\n[Remote Rule]\n")
+	for _, rule := range remote {
+		builder.WriteString(rule + "\n")
+	}
+	builder.WriteString("\n[Rule]\n")
+	for _, rule := range rules {
+		builder.WriteString(rule + "\n")
+	}
+
+	return builder.String(), nil
+}
+
+func (l *LoonConverter) ConvertList(nodes []*model.Node) (string, error) {
+	var builder strings.Builder
+	for _, node := range nodes {
+		proxy, err := l.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		builder.WriteString(proxy.(string) + "\n")
+	}
+	return builder.String(), nil
+}
+
+func (l *LoonConverter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (l *LoonConverter) FileName() string {
+	return "loon.conf"
+}
+
+func (l *LoonConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	if !loonSupported[node.Type] {
+		return "", fmt.Errorf("node type %s is not supported by loon", node.Type)
+	}
+
+	var parts []string
+	switch node.Type {
+	case model.TypeSS:
+		parts = []string{"Shadowsocks", node.Server, strconv.Itoa(node.Port), node.Cipher, strconv.Quote(node.Password)}
+		switch node.Plugin {
+		case "":
+		case "obfs":
+			parts = append(parts, "obfs-name="+defaultIfEmpty(node.PluginOpts["mode"], "http"))
+			if host := node.PluginOpts["host"]; host != "" {
+				parts = append(parts, "obfs-host="+host)
+			}
+		default:
+			return "", fmt.Errorf("ss plugin %s is not supported by loon", node.Plugin)
+		}
+
+	case model.TypeSSR:
+		parts = []string{"ShadowsocksR", node.Server, strconv.Itoa(node.Port), node.Cipher, strconv.Quote(node.Password),
+			"protocol=" + node.Protocol,
+			"protocol-param=" + node.ProtocolParam,
+			"obfs=" + node.Obfs,
+			"obfs-param=" + node.ObfsParam,
+		}
+
+	case model.TypeVmess:
+		parts = []string{"vmess", node.Server, strconv.Itoa(node.Port), defaultIfEmpty(node.Cipher, "auto"), strconv.Quote(node.UUID)}
+		transport, err := l.getTransportOpts(node)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+		parts = append(parts, l.getTLSOpts(node, node.TLS)...)
+		parts = append(parts, fmt.Sprintf("alterId=%d", node.AlterID))
+
+	case model.TypeVless:
+		parts = []string{"VLESS", node.Server, strconv.Itoa(node.Port), strconv.Quote(node.UUID)}
+		transport, err := l.getTransportOpts(node)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+		parts = append(parts, l.getTLSOpts(node, node.TLS)...)
+		if node.Flow != "" {
+			parts = append(parts, "flow="+node.Flow)
+		}
+		if node.RealityPublicKey != "" {
+			parts = append(parts, "public-key="+strconv.Quote(node.RealityPublicKey))
+			if node.RealityShortID != "" {
+				parts = append(parts, "short-id="+node.RealityShortID)
+			}
+		}
+
+	case model.TypeTrojan:
+		parts = []string{"trojan", node.Server, strconv.Itoa(node.Port), strconv.Quote(node.Password)}
+		transport, err := l.getTransportOpts(node)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+		parts = append(parts, l.getTLSOpts(node, true)[1:]...)
+
+	case model.TypeHysteria2:
+		parts = []string{"Hysteria2", node.Server, strconv.Itoa(node.Port), strconv.Quote(node.Password)}
+		if node.Obfs == "salamander" {
+			parts = append(parts, "salamander-password="+node.ObfsPassword)
+		}
+		parts = append(parts, l.getTLSOpts(node, true)[1:]...)
+		if down, err := strconv.Atoi(node.Down); err == nil {
+			parts = append(parts, fmt.Sprintf("download-bandwidth=%d", down))
+		}
+
+	case model.TypeSocks5, model.TypeHTTP:
+		proxyType := string(node.Type)
+		if node.Type == model.TypeHTTP && node.TLS {
+			proxyType = "https"
+		}
+		parts = []string{proxyType, node.Server, strconv.Itoa(node.Port)}
+		if node.Username != "" {
+			parts = append(parts, node.Username, strconv.Quote(node.Password))
+		}
+		if node.Type == model.TypeSocks5 {
+			parts = append(parts, fmt.Sprintf("over-tls=%v", node.TLS))
+		}
+		if node.TLS {
+			parts = append(parts, l.getTLSOpts(node, true)[1:]...)
+		}
+
+	case model.TypeWireGuard:
+		parts = []string{"wireguard"}
+		if node.IP != "" {
+			parts = append(parts, "interface-ip="+node.IP)
+		}
+		if node.IPv6 != "" {
+			parts = append(parts, "interface-ipv6="+node.IPv6)
+		}
+		parts = append(parts, "private-key="+strconv.Quote(node.PrivateKey))
+		if node.MTU > 0 {
+			parts = append(parts, fmt.Sprintf("mtu=%d", node.MTU))
+		}
+		peer := []string{
+			"public-key=" + strconv.Quote(node.PublicKey),
+			"allowed-ips=" + strconv.Quote(defaultIfEmpty(strings.Join(node.AllowedIPs, ","), "0.0.0.0/0,::/0")),
+			"endpoint=" + net.JoinHostPort(node.Server, strconv.Itoa(node.Port)),
+		}
+		if node.PreSharedKey != "" {
+			peer = append(peer, "preshared-key="+strconv.Quote(node.PreSharedKey))
+		}
+		if len(node.Reserved) > 0 {
+			reserved := make([]string, 0, len(node.Reserved))
+			for _, b := range node.Reserved {
+				reserved = append(reserved, strconv.Itoa(b))
+			}
+			peer = append(peer, fmt.Sprintf("reserved=[%s]", strings.Join(reserved, ",")))
+		}
+		parts = append(parts, fmt.Sprintf("peers=[{%s}]", strings.Join(peer, ",")))
+	}
+
+	if node.TFO {
+		parts = append(parts, "fast-open=true")
+	}
+	if node.UDP && node.Type != model.TypeWireGuard {
+		parts = append(parts, "udp=true")
+	}
+
+	return fmt.Sprintf("%s = %s", node.Name, strings.Join(parts, ",")), nil
+}
+
+// getTransportOpts 生成 transport 参数，Loon 支持 tcp/ws/http
+func (l *LoonConverter) getTransportOpts(node *model.Node) ([]string, error) {
+	switch node.Network {
+	case "", "tcp":
+		return []string{"transport=tcp"}, nil
+	case "ws":
+		opts := []string{"transport=ws", "path=" + defaultIfEmpty(node.WsPath, "/")}
+		if host := node.WsHeaders["Host"]; host != "" {
+			opts = append(opts, "host="+host)
+		}
+		return opts, nil
+	default:
+		return nil, fmt.Errorf("transport %s is not supported by loon", node.Network)
+	}
+}
+
+// getTLSOpts 生成 over-tls/sni/skip-cert-verify 参数
+func (l *LoonConverter) getTLSOpts(node *model.Node, tls bool) []string {
+	if !tls {
+		return []string{"over-tls=false"}
+	}
+	return []string{
+		"over-tls=true",
+		"sni=" + defaultIfEmpty(node.SNI, node.Server),
+		fmt.Sprintf("skip-cert-verify=%v", node.AllowInsecure),
+	}
+}
+
+// getProxyGroup 生成 [Proxy Group] 中的一行
+func (l *LoonConverter) getProxyGroup(group config.ProxyGroup, nodes []*model.Node) (string, error) {
+	proxies, err := groupProxies(group, nodes)
+	if err != nil {
+		return "", err
+	}
+
+	parts := append([]string{group.Type}, proxies...)
+	if group.Type != "select" {
+		if group.URL != "" {
+			parts = append(parts, "url="+group.URL)
+		}
+		if group.Interval > 0 {
+			parts = append(parts, fmt.Sprintf("interval=%d", group.Interval))
+		}
+		if group.Tolerance > 0 && group.Type == "url-test" {
+			parts = append(parts, fmt.Sprintf("tolerance=%d", group.Tolerance))
+		}
+		if group.Type == "load-balance" {
+			parts = append(parts, "algorithm=round-robin")
+		}
+	}
+
+	return fmt.Sprintf("%s = %s", group.Name, strings.Join(parts, ",")), nil
+}
+
+// getRules 远程规则集写入 [Remote Rule]，其余规则写入 [Rule]
+func (l *LoonConverter) getRules(clashConfig *config.ClashConfig) ([]string, []string) {
+	providers := make(map[string]config.RuleProvider)
+	for _, provider := range clashConfig.RuleProviders {
+		providers[provider.Name] = provider
+	}
+
+	remote := make([]string, 0)
+	rules := make([]string, 0)
+	referenced := make(map[string]bool)
+	final := ""
+	for _, ruleset := range clashConfig.RuleSets {
+		if provider, found := providers[ruleset.Provider]; found {
+			if provider.Format == config.FormatText && provider.Behavior == config.BehaviorClassical {
+				if !referenced[provider.Name] {
+					referenced[provider.Name] = true
+					remote = append(remote, fmt.Sprintf("%s, policy=%s, tag=%s, enabled=true",
+						provider.URL, ruleset.Strategy, provider.Name))
+				}
+				continue
+			}
+			if ruleset.Type == "RULE-SET" {
+				log.Printf("loon 不支持 %s 格式的规则集 %s", provider.Format, provider.URL)
+				continue
+			}
+		}
+
+		if ruleset.Type == "MATCH" || ruleset.Type == "FINAL" {
+			final = ruleset.Strategy
+			continue
+		}
+		ruleType, ok := loonRuleTypes[ruleset.Type]
+		if !ok || (ruleset.Type == "GEOIP" && strings.EqualFold(ruleset.Pararm, "LAN")) {
+			continue
+		}
+
+		parts := []string{ruleType, ruleset.Pararm, ruleset.Strategy}
+		if ruleset.NoResolve != "" {
+			parts = append(parts, ruleset.NoResolve)
+		}
+		rules = append(rules, strings.Join(parts, ","))
+	}
+
+	if final == "" {
+		final = "DIRECT"
+		if len(clashConfig.ProxyGroups) > 0 {
+			final = clashConfig.ProxyGroups[0].Name
+		}
+	}
+	rules = append(rules, "FINAL,"+final)
+
+	return remote, rules
+}
@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestLoonConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{
+			Type: model.TypeSS, Name: "香港 01", Server: "hk.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass",
+			Plugin: "obfs", PluginOpts: map[string]string{"mode": "http", "host": "bing.com"},
+		},
+		{
+			Type: model.TypeVless, Name: "日本 01", Server: "jp.example.com", Port: 443, UUID: "uuid", TLS: true, SNI: "www.microsoft.com",
+			Flow: "xtls-rprx-vision", RealityPublicKey: "pbk", RealityShortID: "sid",
+		},
+		{Type: model.TypeTUIC, Name: "TUIC", Server: "tuic.example.com", Port: 443, UUID: "uuid", Password: "pass"},
+	}
+	clashConfig := &config.ClashConfig{
+		ProxyGroups: []config.ProxyGroup{
+			{Name: "节点选择", Type: "select", Proxies: []string{"[]自动选择", ".*"}},
+			{Name: "自动选择", Type: "url-test", Proxies: []string{".*"}, URL: "http://www.gstatic.com/generate_204", Interval: 300, Tolerance: 50},
+		},
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "ad.com", Strategy: "REJECT", Provider: "BanAD"},
+			{Type: "GEOIP", Pararm: "CN", Strategy: "DIRECT"},
+			{Type: "MATCH", Strategy: "节点选择"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "BanAD", Behavior: config.BehaviorClassical, Format: config.FormatText, URL: "https://example.com/BanAD.list"},
+		},
+	}
+
+	result, err := NewLoonConverter(&BaseInfo{}).Convert(nodes, clashConfig)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"香港 01 = Shadowsocks,hk.example.com,8388,aes-128-gcm,\"pass\",obfs-name=http,obfs-host=bing.com\n",
+		"日本 01 = VLESS,jp.example.com,443,\"uuid\",transport=tcp,over-tls=true,sni=www.microsoft.com,skip-cert-verify=false,flow=xtls-rprx-vision,public-key=\"pbk\",short-id=sid\n",
+		"节点选择 = select,自动选择,香港 01,日本 01\n",
+		"自动选择 = url-test,香港 01,日本 01,url=http://www.gstatic.com/generate_204,interval=300,tolerance=50\n",
+		"[Remote Rule]\nhttps://example.com/BanAD.list, policy=REJECT, tag=BanAD, enabled=true\n",
+		"[Rule]\nGEOIP,CN,DIRECT\nFINAL,节点选择\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "TUIC") {
+		t.Errorf("unsupported tuic node should be skipped:\n%s", result)
+	}
+}
@@ -0,0 +1,368 @@
+// internal/subscription/converter/quantumultx.go
+package converter
+
+import (
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// quanXRuleTypes Clash 规则类型对应的 Quantumult X 规则类型
+var quanXRuleTypes = map[string]string{
+	"DOMAIN":         "host",
+	"DOMAIN-SUFFIX":  "host-suffix",
+	"DOMAIN-KEYWORD": "host-keyword",
+	"IP-CIDR":        "ip-cidr",
+	"IP-CIDR6":       "ip6-cidr",
+	"GEOIP":          "geoip",
+	"USER-AGENT":     "user-agent",
+}
+
+// quanXGroupTypes Clash 策略组类型对应的 Quantumult X 策略类型
+var quanXGroupTypes = map[string]string{
+	"select":       "static",
+	"url-test":     "url-latency-benchmark",
+	"fallback":     "available",
+	"load-balance": "round-robin",
+}
+
+type QuantumultXConverter struct {
+	info *BaseInfo
+}
+
+func NewQuantumultXConverter(info *BaseInfo) *QuantumultXConverter {
+	return &QuantumultXConverter{
+		info: info,
+	}
+}
+
+func (q *QuantumultXConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	var builder strings.Builder
+
+	// 写入基础配置
+	builder.WriteString("[general]\n")
+	checkURL := "http://www.gstatic.com/generate_204"
+	for _, group := range clashConfig.ProxyGroups {
+		if group.URL != "" {
+			checkURL = group.URL
+			break
+		}
+	}
+	builder.WriteString(fmt.Sprintf("server_check_url=%s\n\n", checkURL))
+
+	builder.WriteString("[dns]\n")
+	builder.WriteString("server=223.5.5.5\n")
+	builder.WriteString("server=114.114.114.114\n\n")
+
+	// 写入节点
+	builder.WriteString("[server_local]\n")
+	converted := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		proxy, err := q.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		builder.WriteString(proxy.(string) + "\n")
+		converted = append(converted, node)
+	}
+
+	// 写入策略组
+	builder.WriteString("\n[policy]\n")
+	for _, group := range clashConfig.ProxyGroups {
+		line, err := q.getPolicy(group, converted)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	// 写入规则
+	remote, local := q.getRules(clashConfig)
+	builder.WriteString("\n[filter_remote]\n")
+	for _, rule := range remote {
+		builder.WriteString(rule + "\n")
+	}
+	builder.WriteString("\n[filter_local]\n")
+	for _, rule := range local {
+		builder.WriteString(rule + "\n")
+	}
+
+	return builder.String(), nil
+}
+
+func (q *QuantumultXConverter) ConvertList(nodes []*model.Node) (string, error) {
+	var builder strings.Builder
+	for _, node := range nodes {
+		proxy, err := q.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		builder.WriteString(proxy.(string) + "\n")
+	}
+	return builder.String(), nil
+}
+
+func (q *QuantumultXConverter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (q *QuantumultXConverter) FileName() string {
+	return "quantumultx.conf"
+}
+
+func (q *QuantumultXConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	address := net.JoinHostPort(node.Server, strconv.Itoa(node.Port))
+	var parts []string
+
+	switch node.Type {
+	case model.TypeSS:
+		parts = []string{
+			"shadowsocks=" + address,
+			"method=" + node.Cipher,
+			"password=" + node.Password,
+		}
+		switch node.Plugin {
+		case "":
+		case "obfs":
+			parts = append(parts, "obfs="+defaultIfEmpty(node.PluginOpts["mode"], "http"))
+			if host := node.PluginOpts["host"]; host != "" {
+				parts = append(parts, "obfs-host="+host)
+			}
+		case "v2ray-plugin":
+			obfs := "ws"
+			if node.PluginOpts["tls"] == "true" {
+				obfs = "wss"
+			}
+			parts = append(parts, "obfs="+obfs)
+			if host := node.PluginOpts["host"]; host != "" {
+				parts = append(parts, "obfs-host="+host)
+			}
+			parts = append(parts, "obfs-uri="+defaultIfEmpty(node.PluginOpts["path"], "/"))
+		default:
+			return "", fmt.Errorf("ss plugin %s is not supported by quantumult x", node.Plugin)
+		}
+
+	case model.TypeSSR:
+		parts = []string{
+			"shadowsocks=" + address,
+			"method=" + node.Cipher,
+			"password=" + node.Password,
+			"ssr-protocol=" + node.Protocol,
+			"ssr-protocol-param=" + node.ProtocolParam,
+			"obfs=" + node.Obfs,
+			"obfs-host=" + node.ObfsParam,
+		}
+
+	case model.TypeVmess:
+		method := node.Cipher
+		if method == "" || method == "auto" {
+			method = "chacha20-poly1305"
+		}
+		parts = []string{
+			"vmess=" + address,
+			"method=" + method,
+			"password=" + node.UUID,
+		}
+		transport, err := q.getTransportOpts(node, node.TLS)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+		if node.AlterID != 0 {
+			parts = append(parts, "aead=false")
+		}
+
+	case model.TypeVless:
+		parts = []string{
+			"vless=" + address,
+			"method=none",
+			"password=" + node.UUID,
+		}
+		transport, err := q.getTransportOpts(node, node.TLS)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+		if node.Flow != "" {
+			parts = append(parts, "vless-flow="+node.Flow)
+		}
+		if node.RealityPublicKey != "" {
+			parts = append(parts, "reality-base64-pubkey="+node.RealityPublicKey)
+			if node.RealityShortID != "" {
+				parts = append(parts, "reality-hex-shortid="+node.RealityShortID)
+			}
+		}
+
+	case model.TypeTrojan:
+		parts = []string{
+			"trojan=" + address,
+			"password=" + node.Password,
+		}
+		transport, err := q.getTransportOpts(node, true)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, transport...)
+
+	case model.TypeHTTP, model.TypeSocks5:
+		parts = []string{string(node.Type) + "=" + address}
+		if node.Username != "" {
+			parts = append(parts, "username="+node.Username, "password="+node.Password)
+		}
+		if node.TLS {
+			parts = append(parts, "over-tls=true", "tls-host="+defaultIfEmpty(node.SNI, node.Server),
+				fmt.Sprintf("tls-verification=%v", !node.AllowInsecure))
+		}
+
+	default:
+		return "", fmt.Errorf("unsupported node type: %s", node.Type)
+	}
+
+	if node.TFO {
+		parts = append(parts, "fast-open=true")
+	}
+	if node.UDP {
+		parts = append(parts, "udp-relay=true")
+	}
+	parts = append(parts, "tag="+node.Name)
+
+	return strings.Join(parts, ", "), nil
+}
+
+// getTransportOpts 生成 obfs/over-tls 参数
+//
+// Quantumult X 用 obfs=ws/wss 表示 WebSocket，obfs=over-tls 表示 TCP+TLS
+func (q *QuantumultXConverter) getTransportOpts(node *model.Node, tls bool) ([]string, error) {
+	opts := make([]string, 0)
+	switch node.Network {
+	case "", "tcp":
+		if tls {
+			// trojan 的 TLS 用 over-tls 表示，vmess/vless 用 obfs=over-tls
+			if node.Type == model.TypeTrojan {
+				opts = append(opts, "over-tls=true", "tls-host="+defaultIfEmpty(node.SNI, node.Server))
+			} else {
+				opts = append(opts, "obfs=over-tls", "obfs-host="+defaultIfEmpty(node.SNI, node.Server))
+			}
+		}
+	case "ws":
+		obfs := "ws"
+		if tls {
+			obfs = "wss"
+		}
+		opts = append(opts, "obfs="+obfs)
+		host := defaultIfEmpty(node.WsHeaders["Host"], node.SNI)
+		if host != "" {
+			opts = append(opts, "obfs-host="+host)
+		}
+		opts = append(opts, "obfs-uri="+defaultIfEmpty(node.WsPath, "/"))
+	default:
+		return nil, fmt.Errorf("transport %s is not supported by quantumult x", node.Network)
+	}
+
+	if tls {
+		opts = append(opts, fmt.Sprintf("tls-verification=%v", !node.AllowInsecure))
+	}
+	return opts, nil
+}
+
+// getPolicy 生成 [policy] 中的一行
+func (q *QuantumultXConverter) getPolicy(group config.ProxyGroup, nodes []*model.Node) (string, error) {
+	proxies, err := groupProxies(group, nodes)
+	if err != nil {
+		return "", err
+	}
+
+	policyType, ok := quanXGroupTypes[group.Type]
+	if !ok {
+		policyType = "static"
+	}
+
+	parts := []string{fmt.Sprintf("%s=%s", policyType, group.Name)}
+	for _, proxy := range proxies {
+		parts = append(parts, quanXPolicyName(proxy))
+	}
+	if policyType != "static" {
+		if group.URL != "" {
+			parts = append(parts, "server-check-url="+group.URL)
+		}
+		if group.Interval > 0 {
+			parts = append(parts, fmt.Sprintf("check-interval=%d", group.Interval))
+		}
+		if group.Tolerance > 0 && policyType == "url-latency-benchmark" {
+			parts = append(parts, fmt.Sprintf("tolerance=%d", group.Tolerance))
+		}
+	}
+
+	return strings.Join(parts, ", "), nil
+}
+
+// getRules 远程规则集写入 [filter_remote]，其余规则写入 [filter_local]
+func (q *QuantumultXConverter) getRules(clashConfig *config.ClashConfig) ([]string, []string) {
+	providers := make(map[string]config.RuleProvider)
+	for _, provider := range clashConfig.RuleProviders {
+		providers[provider.Name] = provider
+	}
+
+	remote := make([]string, 0)
+	local := make([]string, 0)
+	referenced := make(map[string]bool)
+	final := ""
+	for _, ruleset := range clashConfig.RuleSets {
+		if provider, found := providers[ruleset.Provider]; found {
+			if provider.Format == config.FormatText && provider.Behavior == config.BehaviorClassical {
+				if !referenced[provider.Name] {
+					referenced[provider.Name] = true
+					remote = append(remote, fmt.Sprintf("%s, tag=%s, force-policy=%s, update-interval=%d, opt-parser=false, enabled=true",
+						provider.URL, provider.Name, quanXPolicyName(ruleset.Strategy), provider.Interval))
+				}
+				continue
+			}
+			if ruleset.Type == "RULE-SET" {
+				log.Printf("quantumult x 不支持 %s 格式的规则集 %s", provider.Format, provider.URL)
+				continue
+			}
+		}
+
+		if ruleset.Type == "MATCH" || ruleset.Type == "FINAL" {
+			final = ruleset.Strategy
+			continue
+		}
+		ruleType, ok := quanXRuleTypes[ruleset.Type]
+		if !ok || (ruleset.Type == "GEOIP" && strings.EqualFold(ruleset.Pararm, "LAN")) {
+			continue
+		}
+
+		parts := []string{ruleType, ruleset.Pararm, quanXPolicyName(ruleset.Strategy)}
+		if ruleset.NoResolve != "" {
+			parts = append(parts, ruleset.NoResolve)
+		}
+		local = append(local, strings.Join(parts, ", "))
+	}
+
+	if final == "" {
+		final = "DIRECT"
+		if len(clashConfig.ProxyGroups) > 0 {
+			final = clashConfig.ProxyGroups[0].Name
+		}
+	}
+	local = append(local, "final, "+quanXPolicyName(final))
+
+	return remote, local
+}
+
+// quanXPolicyName 将内置策略转换为 Quantumult X 的写法
+func quanXPolicyName(name string) string {
+	switch name {
+	case "DIRECT":
+		return "direct"
+	case "REJECT":
+		return "reject"
+	}
+	return name
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestQuantumultXConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{
+			Type: model.TypeSS, Name: "香港 01", Server: "hk.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass",
+			Plugin: "obfs", PluginOpts: map[string]string{"mode": "http", "host": "bing.com"}, UDP: true,
+		},
+		{
+			Type: model.TypeVmess, Name: "日本 01", Server: "jp.example.com", Port: 443, UUID: "uuid", TLS: true,
+			Network: "ws", WsPath: "/ws", WsHeaders: map[string]string{"Host": "cdn.example.com"},
+		},
+		{Type: model.TypeTrojan, Name: "美国 01", Server: "us.example.com", Port: 443, Password: "pass", SNI: "us.example.com"},
+		{Type: model.TypeHysteria2, Name: "HY2", Server: "hy2.example.com", Port: 443, Password: "pass"},
+	}
+	clashConfig := &config.ClashConfig{
+		ProxyGroups: []config.ProxyGroup{
+			{Name: "节点选择", Type: "select", Proxies: []string{"[]自动选择", "[]DIRECT", ".*"}},
+			{Name: "自动选择", Type: "url-test", Proxies: []string{"^(?!.*美国).*"}, URL: "http://www.gstatic.com/generate_204", Interval: 300, Tolerance: 50},
+			{Name: "故障转移", Type: "fallback", Proxies: []string{"美国"}},
+		},
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "ad.com", Strategy: "REJECT", Provider: "BanAD"},
+			{Type: "DOMAIN-SUFFIX", Pararm: "google.com", Strategy: "节点选择"},
+			{Type: "IP-CIDR", Pararm: "10.0.0.0/8", Strategy: "DIRECT", NoResolve: "no-resolve"},
+			{Type: "MATCH", Strategy: "节点选择"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "BanAD", Behavior: config.BehaviorClassical, Format: config.FormatText, URL: "https://example.com/BanAD.list", Interval: 86400},
+		},
+	}
+
+	result, err := NewQuantumultXConverter(&BaseInfo{}).Convert(nodes, clashConfig)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"shadowsocks=hk.example.com:8388, method=aes-128-gcm, password=pass, obfs=http, obfs-host=bing.com, udp-relay=true, tag=香港 01\n",
+		"vmess=jp.example.com:443, method=chacha20-poly1305, password=uuid, obfs=wss, obfs-host=cdn.example.com, obfs-uri=/ws, tls-verification=true, tag=日本 01\n",
+		"trojan=us.example.com:443, password=pass, over-tls=true, tls-host=us.example.com, tls-verification=true, tag=美国 01\n",
+		"static=节点选择, 自动选择, direct, 香港 01, 日本 01, 美国 01\n",
+		"url-latency-benchmark=自动选择, 香港 01, 日本 01, server-check-url=http://www.gstatic.com/generate_204, check-interval=300, tolerance=50\n",
+		"available=故障转移, 美国 01\n",
+		"https://example.com/BanAD.list, tag=BanAD, force-policy=reject, update-interval=86400, opt-parser=false, enabled=true\n",
+		"[filter_local]\nhost-suffix, google.com, 节点选择\nip-cidr, 10.0.0.0/8, direct, no-resolve\nfinal, 节点选择\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "HY2") {
+		t.Errorf("unsupported hysteria2 node should be skipped:\n%s", result)
+	}
+}
@@ -0,0 +1,756 @@
+// internal/subscription/converter/singbox.go
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"log"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	singBoxGeoIPURL   = "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-%s.srs"
+	singBoxGeoSiteURL = "https://raw.githubusercontent.com/SagerNet/sing-geosite/rule-set/geosite-%s.srs"
+)
+
+type SingBoxConverter struct {
+	info     *BaseInfo
+	template map[string]interface{} // 基础模板，覆盖默认的 dns/inbounds 等配置
+}
+
+func NewSingBoxConverter(info *BaseInfo) *SingBoxConverter {
+	return &SingBoxConverter{
+		info: info,
+	}
+}
+
+// SetTemplate 设置 sing-box 基础模板(JSON)
+func (c *SingBoxConverter) SetTemplate(data []byte) error {
+	template := make(map[string]interface{})
+	if err := json.Unmarshal(data, &template); err != nil {
+		return fmt.Errorf("failed to unmarshal sing-box template: %v", err)
+	}
+	c.template = template
+	return nil
+}
+
+func (c *SingBoxConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	outbounds, converted := c.convertNodes(nodes)
+
+	// 添加策略组，sing-box 没有 REJECT 出站，默认选中 REJECT 的策略组按拦截处理
+	rejects := map[string]bool{"REJECT": true}
+	groups := make([]interface{}, 0, len(clashConfig.ProxyGroups))
+	for _, configProxyGroup := range clashConfig.ProxyGroups {
+		proxies, err := groupProxies(configProxyGroup, converted)
+		if err != nil {
+			return "", err
+		}
+		if proxies[0] == "REJECT" {
+			rejects[configProxyGroup.Name] = true
+		}
+		proxies = removeString(proxies, "REJECT")
+		if len(proxies) == 0 {
+			proxies = append(proxies, "DIRECT")
+		}
+
+		group := map[string]interface{}{
+			"tag":       configProxyGroup.Name,
+			"outbounds": proxies,
+		}
+		switch configProxyGroup.Type {
+		case "url-test", "fallback", "load-balance":
+			group["type"] = "urltest"
+			if configProxyGroup.URL != "" {
+				group["url"] = configProxyGroup.URL
+			}
+			if configProxyGroup.Interval > 0 {
+				group["interval"] = fmt.Sprintf("%ds", configProxyGroup.Interval)
+			}
+			if configProxyGroup.Tolerance > 0 {
+				group["tolerance"] = configProxyGroup.Tolerance
+			}
+		default:
+			group["type"] = "selector"
+		}
+		groups = append(groups, group)
+	}
+
+	outbounds = append(groups, outbounds...)
+	outbounds = append(outbounds, map[string]interface{}{"type": "direct", "tag": "DIRECT"})
+
+	final := "DIRECT"
+	if len(clashConfig.ProxyGroups) > 0 {
+		final = clashConfig.ProxyGroups[0].Name
+	}
+	rules, ruleSets, matchStrategy := c.getRules(clashConfig, rejects)
+	if rejects[matchStrategy] {
+		rules = append(rules, map[string]interface{}{"action": "reject"})
+	} else if matchStrategy != "" {
+		final = matchStrategy
+	}
+
+	result := c.defaultConfig(final)
+	result["outbounds"] = outbounds
+	route := result["route"].(map[string]interface{})
+	route["rules"] = append(route["rules"].([]interface{}), rules...)
+	route["rule_set"] = ruleSets
+	route["final"] = final
+
+	c.applyTemplate(result)
+
+	return marshalSingBox(result)
+}
+
+func (c *SingBoxConverter) ConvertList(nodes []*model.Node) (string, error) {
+	outbounds, _ := c.convertNodes(nodes)
+	return marshalSingBox(map[string]interface{}{"outbounds": outbounds})
+}
+
+func (c *SingBoxConverter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (c *SingBoxConverter) FileName() string {
+	return "sing-box.json"
+}
+
+// convertNodes 转换所有节点，跳过 sing-box 不支持的类型
+func (c *SingBoxConverter) convertNodes(nodes []*model.Node) ([]interface{}, []*model.Node) {
+	outbounds := make([]interface{}, 0, len(nodes))
+	converted := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		outbound, err := c.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		outbounds = append(outbounds, outbound)
+		converted = append(converted, node)
+	}
+	return outbounds, converted
+}
+
+func (c *SingBoxConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	outbound := map[string]interface{}{
+		"tag":         node.Name,
+		"server":      node.Server,
+		"server_port": node.Port,
+	}
+
+	switch node.Type {
+	case model.TypeSS:
+		outbound["type"] = "shadowsocks"
+		outbound["method"] = node.Cipher
+		outbound["password"] = node.Password
+		if node.Plugin != "" {
+			plugin, opts := singBoxPlugin(node)
+			outbound["plugin"] = plugin
+			outbound["plugin_opts"] = opts
+		}
+		if node.UDPOverTCP {
+			outbound["udp_over_tcp"] = true
+		}
+
+	case model.TypeVmess:
+		outbound["type"] = "vmess"
+		outbound["uuid"] = node.UUID
+		outbound["alter_id"] = node.AlterID
+		outbound["security"] = defaultIfEmpty(node.Cipher, "auto")
+		c.setTLS(outbound, node, node.TLS)
+		c.setTransport(outbound, node)
+		if node.PacketEncoding != "" {
+			outbound["packet_encoding"] = node.PacketEncoding
+		}
+
+	case model.TypeVless:
+		outbound["type"] = "vless"
+		outbound["uuid"] = node.UUID
+		if node.Flow != "" {
+			outbound["flow"] = node.Flow
+		}
+		c.setTLS(outbound, node, node.TLS)
+		c.setTransport(outbound, node)
+		if node.PacketEncoding != "" {
+			outbound["packet_encoding"] = node.PacketEncoding
+		}
+
+	case model.TypeTrojan:
+		outbound["type"] = "trojan"
+		outbound["password"] = node.Password
+		c.setTLS(outbound, node, true)
+		c.setTransport(outbound, node)
+
+	case model.TypeHysteria2:
+		outbound["type"] = "hysteria2"
+		outbound["password"] = node.Password
+		if node.Ports != "" {
+			ports := make([]string, 0)
+			for _, port := range strings.Split(node.Ports, ",") {
+				ports = append(ports, strings.ReplaceAll(strings.TrimSpace(port), "-", ":"))
+			}
+			outbound["server_ports"] = ports
+		}
+		if up, err := strconv.Atoi(node.Up); err == nil {
+			outbound["up_mbps"] = up
+		}
+		if down, err := strconv.Atoi(node.Down); err == nil {
+			outbound["down_mbps"] = down
+		}
+		if node.Obfs != "" {
+			outbound["obfs"] = map[string]interface{}{
+				"type":     node.Obfs,
+				"password": node.ObfsPassword,
+			}
+		}
+		c.setTLS(outbound, node, true)
+
+	case model.TypeTUIC:
+		outbound["type"] = "tuic"
+		outbound["uuid"] = node.UUID
+		outbound["password"] = node.Password
+		if node.CongestionControl != "" {
+			outbound["congestion_control"] = node.CongestionControl
+		}
+		if node.UDPRelayMode != "" {
+			outbound["udp_relay_mode"] = node.UDPRelayMode
+		}
+		if node.ReduceRTT {
+			outbound["zero_rtt_handshake"] = true
+		}
+		c.setTLS(outbound, node, true)
+
+	case model.TypeAnyTLS:
+		outbound["type"] = "anytls"
+		outbound["password"] = node.Password
+		c.setTLS(outbound, node, true)
+
+	case model.TypeSocks5:
+		outbound["type"] = "socks"
+		outbound["version"] = "5"
+		if node.Username != "" {
+			outbound["username"] = node.Username
+			outbound["password"] = node.Password
+		}
+
+	case model.TypeHTTP:
+		outbound["type"] = "http"
+		if node.Username != "" {
+			outbound["username"] = node.Username
+			outbound["password"] = node.Password
+		}
+		c.setTLS(outbound, node, node.TLS)
+
+	default:
+		return nil, fmt.Errorf("unsupported node type: %s", node.Type)
+	}
+
+	if node.DialerProxy != "" {
+		outbound["detour"] = node.DialerProxy
+	}
+
+	return outbound, nil
+}
+
+// setTLS 设置 tls 字段，REALITY 需要同时启用 uTLS
+func (c *SingBoxConverter) setTLS(outbound map[string]interface{}, node *model.Node, enabled bool) {
+	if !enabled {
+		return
+	}
+	tls := map[string]interface{}{
+		"enabled": true,
+	}
+	if node.SNI != "" {
+		tls["server_name"] = node.SNI
+	}
+	if node.AllowInsecure {
+		tls["insecure"] = true
+	}
+	if node.DisableSNI {
+		tls["disable_sni"] = true
+	}
+	if len(node.ALPN) > 0 {
+		tls["alpn"] = node.ALPN
+	}
+	fingerprint := node.ClientFingerprint
+	if node.RealityPublicKey != "" {
+		fingerprint = defaultIfEmpty(fingerprint, "chrome")
+		reality := map[string]interface{}{
+			"enabled":    true,
+			"public_key": node.RealityPublicKey,
+		}
+		if node.RealityShortID != "" {
+			reality["short_id"] = node.RealityShortID
+		}
+		tls["reality"] = reality
+	}
+	if fingerprint != "" {
+		tls["utls"] = map[string]interface{}{
+			"enabled":     true,
+			"fingerprint": fingerprint,
+		}
+	}
+	outbound["tls"] = tls
+}
+
+// setTransport 设置 ws/httpupgrade/grpc/h2 传输层
+func (c *SingBoxConverter) setTransport(outbound map[string]interface{}, node *model.Node) {
+	var transport map[string]interface{}
+	switch node.Network {
+	case "ws":
+		transport = map[string]interface{}{
+			"type": "ws",
+			"path": defaultIfEmpty(node.WsPath, "/"),
+		}
+		if len(node.WsHeaders) > 0 {
+			transport["headers"] = node.WsHeaders
+		}
+		if node.WsMaxEarlyData > 0 {
+			transport["max_early_data"] = node.WsMaxEarlyData
+			transport["early_data_header_name"] = defaultIfEmpty(node.WsEarlyDataHeader, "Sec-WebSocket-Protocol")
+		}
+	case "httpupgrade":
+		transport = map[string]interface{}{
+			"type": "httpupgrade",
+			"path": defaultIfEmpty(node.WsPath, "/"),
+		}
+		if host := node.WsHeaders["Host"]; host != "" {
+			transport["host"] = host
+		}
+	case "grpc":
+		transport = map[string]interface{}{
+			"type":         "grpc",
+			"service_name": node.GrpcServiceName,
+		}
+	case "h2":
+		transport = map[string]interface{}{
+			"type": "http",
+			"path": defaultIfEmpty(node.H2Path, "/"),
+		}
+		if len(node.H2Host) > 0 {
+			transport["host"] = node.H2Host
+		}
+	default:
+		return
+	}
+	outbound["transport"] = transport
+}
+
+// singBoxPlugin 将 Clash 风格的插件配置转换为 SIP003 参数
+func singBoxPlugin(node *model.Node) (string, string) {
+	opts := make([]string, 0)
+	switch node.Plugin {
+	case "obfs":
+		opts = append(opts, "obfs="+node.PluginOpts["mode"])
+		if host := node.PluginOpts["host"]; host != "" {
+			opts = append(opts, "obfs-host="+host)
+		}
+		return "obfs-local", strings.Join(opts, ";")
+	case "v2ray-plugin":
+		if node.PluginOpts["mode"] != "" && node.PluginOpts["mode"] != "websocket" {
+			opts = append(opts, "mode="+node.PluginOpts["mode"])
+		}
+		if node.PluginOpts["tls"] == "true" {
+			opts = append(opts, "tls")
+		}
+		for _, key := range []string{"host", "path"} {
+			if value := node.PluginOpts[key]; value != "" {
+				opts = append(opts, key+"="+value)
+			}
+		}
+		return "v2ray-plugin", strings.Join(opts, ";")
+	}
+	// 按键排序，保证输出稳定
+	for _, key := range slices.Sorted(maps.Keys(node.PluginOpts)) {
+		opts = append(opts, key+"="+node.PluginOpts[key])
+	}
+	return node.Plugin, strings.Join(opts, ";")
+}
+
+// singBoxRuleFields Clash 规则类型对应的 sing-box 字段，值为 true 的可以合并到同一条规则
+var singBoxRuleFields = map[string]struct {
+	field     string
+	mergeable bool
+}{
+	"DOMAIN":         {"domain", true},
+	"DOMAIN-SUFFIX":  {"domain_suffix", true},
+	"DOMAIN-KEYWORD": {"domain_keyword", true},
+	"DOMAIN-REGEX":   {"domain_regex", true},
+	"IP-CIDR":        {"ip_cidr", true},
+	"IP-CIDR6":       {"ip_cidr", true},
+	"SRC-IP-CIDR":    {"source_ip_cidr", false},
+	"DST-PORT":       {"port", false},
+	"SRC-PORT":       {"source_port", false},
+	"PROCESS-NAME":   {"process_name", false},
+	"PROCESS-PATH":   {"process_path", false},
+}
+
+// getRules 将 config.RuleSets 转换为 route.rules 与 rule_set，返回 MATCH 对应的策略
+//
+// 远程规则集展开的规则放入以 provider 命名的 inline rule_set，route.rules 只引用标签；
+// 未展开的 RULE-SET 只有 .srs 与 .json 可以作为 remote rule_set 引用。
+// rejects 中的策略转换为 reject 动作。
+func (c *SingBoxConverter) getRules(clashConfig *config.ClashConfig, rejects map[string]bool) ([]interface{}, []interface{}, string) {
+	rules := make([]interface{}, 0)
+	ruleSets := make([]interface{}, 0)
+	ruleSetTags := make(map[string]bool)
+	inlineSets := make(map[string]*singBoxInlineRuleSet)
+	providers := make(map[string]config.RuleProvider)
+	for _, provider := range clashConfig.RuleProviders {
+		providers[provider.Name] = provider
+	}
+	matchStrategy := ""
+
+	var current map[string]interface{}
+	currentStrategy := ""
+	currentRuleSet := ""
+	flush := func() {
+		if current != nil {
+			rules = append(rules, current)
+			current = nil
+		}
+		currentRuleSet = ""
+	}
+	newRule := func(strategy string) map[string]interface{} {
+		rule := make(map[string]interface{})
+		if rejects[strategy] {
+			rule["action"] = "reject"
+		} else {
+			rule["outbound"] = strategy
+		}
+		return rule
+	}
+	addRuleSet := func(ruleSet map[string]interface{}) {
+		tag := ruleSet["tag"].(string)
+		if !ruleSetTags[tag] {
+			ruleSetTags[tag] = true
+			ruleSets = append(ruleSets, ruleSet)
+		}
+	}
+	// useRuleSet 引用 rule_set，同一规则集的连续规则只生成一条
+	useRuleSet := func(tag, strategy string) {
+		if currentRuleSet == tag && currentStrategy == strategy {
+			return
+		}
+		flush()
+		rule := newRule(strategy)
+		rule["rule_set"] = tag
+		rules = append(rules, rule)
+		currentRuleSet, currentStrategy = tag, strategy
+	}
+
+	for _, ruleset := range clashConfig.RuleSets {
+		switch ruleset.Type {
+		case "MATCH", "FINAL":
+			matchStrategy = ruleset.Strategy
+			continue
+
+		case "GEOIP", "GEOSITE":
+			flush()
+			rule := newRule(ruleset.Strategy)
+			code := strings.ToLower(ruleset.Pararm)
+			if ruleset.Type == "GEOIP" && code == "lan" {
+				rule["ip_is_private"] = true
+			} else {
+				tag := fmt.Sprintf("%s-%s", strings.ToLower(ruleset.Type), code)
+				url := fmt.Sprintf(singBoxGeoIPURL, code)
+				if ruleset.Type == "GEOSITE" {
+					url = fmt.Sprintf(singBoxGeoSiteURL, code)
+				}
+				addRuleSet(singBoxRemoteRuleSet(tag, "binary", url))
+				rule["rule_set"] = tag
+			}
+			rules = append(rules, rule)
+			continue
+
+		case "RULE-SET":
+			provider, ok := providers[ruleset.Provider]
+			format := singBoxRuleSetFormat(provider.URL)
+			if !ok || format == "" {
+				log.Printf("跳过规则集 %s: sing-box 只能引用 .srs 或 .json 规则集", ruleset.Pararm)
+				continue
+			}
+			addRuleSet(singBoxRemoteRuleSet(provider.Name, format, provider.URL))
+			useRuleSet(provider.Name, ruleset.Strategy)
+			continue
+		}
+
+		mapping, ok := singBoxRuleFields[ruleset.Type]
+		if !ok {
+			continue
+		}
+
+		var value interface{} = ruleset.Pararm
+		if mapping.field == "port" || mapping.field == "source_port" {
+			port, err := strconv.Atoi(ruleset.Pararm)
+			if err != nil {
+				continue
+			}
+			value = port
+		}
+
+		if ruleset.Provider != "" {
+			inline, ok := inlineSets[ruleset.Provider]
+			if !ok {
+				inline = newSingBoxInlineRuleSet(ruleset.Provider)
+				inlineSets[ruleset.Provider] = inline
+				addRuleSet(inline.ruleSet)
+			}
+			inline.add(mapping.field, value, mapping.mergeable)
+			useRuleSet(ruleset.Provider, ruleset.Strategy)
+			continue
+		}
+
+		if !mapping.mergeable {
+			flush()
+			rule := newRule(ruleset.Strategy)
+			rule[mapping.field] = []interface{}{value}
+			rules = append(rules, rule)
+			continue
+		}
+
+		// 相邻且策略相同的域名/IP 规则合并为一条
+		if current == nil || currentStrategy != ruleset.Strategy {
+			flush()
+			current = newRule(ruleset.Strategy)
+			currentStrategy = ruleset.Strategy
+		}
+		values, _ := current[mapping.field].([]interface{})
+		current[mapping.field] = append(values, value)
+	}
+	flush()
+
+	return rules, ruleSets, matchStrategy
+}
+
+// singBoxInlineRuleSet 由规则集展开的规则组成的 inline rule_set
+type singBoxInlineRuleSet struct {
+	ruleSet map[string]interface{}
+	merged  map[string]interface{} // 域名/IP 规则合并为一条 headless 规则
+}
+
+func newSingBoxInlineRuleSet(tag string) *singBoxInlineRuleSet {
+	return &singBoxInlineRuleSet{
+		ruleSet: map[string]interface{}{
+			"type":  "inline",
+			"tag":   tag,
+			"rules": []interface{}{},
+		},
+	}
+}
+
+// add 添加一条规则，不可合并的字段单独作为一条 headless 规则
+func (s *singBoxInlineRuleSet) add(field string, value interface{}, mergeable bool) {
+	headless := s.ruleSet["rules"].([]interface{})
+	if !mergeable {
+		s.ruleSet["rules"] = append(headless, map[string]interface{}{field: []interface{}{value}})
+		return
+	}
+	if s.merged == nil {
+		s.merged = make(map[string]interface{})
+		s.ruleSet["rules"] = append(headless, s.merged)
+	}
+	values, _ := s.merged[field].([]interface{})
+	s.merged[field] = append(values, value)
+}
+
+// singBoxRemoteRuleSet 远程 rule_set，通过 DIRECT 下载
+func singBoxRemoteRuleSet(tag, format, url string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            "remote",
+		"tag":             tag,
+		"format":          format,
+		"url":             url,
+		"download_detour": "DIRECT",
+	}
+}
+
+// singBoxRuleSetFormat 根据扩展名判断 sing-box 能否直接引用规则集，不能时返回空
+func singBoxRuleSetFormat(url string) string {
+	switch strings.ToLower(path.Ext(strings.SplitN(url, "?", 2)[0])) {
+	case ".srs":
+		return "binary"
+	case ".json":
+		return "source"
+	}
+	return ""
+}
+
+// removeString 去掉切片中等于 value 的元素
+func removeString(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// defaultConfig 默认的 log/dns/inbounds/route 配置
+//
+// 远程 DNS 经 proxyOutbound 出站，为 DIRECT 时不设置 detour，sing-box 不允许 detour 到空的直连出站
+func (c *SingBoxConverter) defaultConfig(proxyOutbound string) map[string]interface{} {
+	remoteDNS := map[string]interface{}{
+		"type":   "https",
+		"tag":    "remote",
+		"server": "1.1.1.1",
+	}
+	if proxyOutbound != "DIRECT" {
+		remoteDNS["detour"] = proxyOutbound
+	}
+	return map[string]interface{}{
+		"log": map[string]interface{}{
+			"level":     "info",
+			"timestamp": true,
+		},
+		"dns": map[string]interface{}{
+			"servers": []interface{}{
+				remoteDNS,
+				map[string]interface{}{
+					"type":   "udp",
+					"tag":    "local",
+					"server": "223.5.5.5",
+				},
+			},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"rule_set": "geosite-cn",
+					"server":   "local",
+				},
+			},
+			"final": "remote",
+		},
+		"inbounds": []interface{}{
+			map[string]interface{}{
+				"type":         "tun",
+				"tag":          "tun-in",
+				"address":      []string{"172.19.0.1/30", "fdfe:dcba:9876::1/126"},
+				"auto_route":   true,
+				"strict_route": true,
+			},
+			map[string]interface{}{
+				"type":        "mixed",
+				"tag":         "mixed-in",
+				"listen":      "127.0.0.1",
+				"listen_port": 7890,
+			},
+		},
+		"route": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{"action": "sniff"},
+				map[string]interface{}{"protocol": "dns", "action": "hijack-dns"},
+			},
+			"auto_detect_interface":   true,
+			"default_domain_resolver": "local",
+		},
+		"experimental": map[string]interface{}{
+			"cache_file": map[string]interface{}{
+				"enabled": true,
+			},
+		},
+	}
+}
+
+// applyTemplate 用基础模板覆盖默认配置
+//
+// outbounds 追加在生成的出站之后，route.rules 插入到生成的规则之前，其余字段直接覆盖
+func (c *SingBoxConverter) applyTemplate(result map[string]interface{}) {
+	for key, value := range c.template {
+		switch key {
+		case "outbounds":
+			if extra, ok := value.([]interface{}); ok {
+				result["outbounds"] = append(result["outbounds"].([]interface{}), extra...)
+			}
+		case "route":
+			templateRoute, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			route := result["route"].(map[string]interface{})
+			for routeKey, routeValue := range templateRoute {
+				switch routeKey {
+				case "rules", "rule_set":
+					if extra, ok := routeValue.([]interface{}); ok {
+						if routeKey == "rules" {
+							route[routeKey] = append(extra, route[routeKey].([]interface{})...)
+						} else {
+							route[routeKey] = append(route[routeKey].([]interface{}), extra...)
+						}
+					}
+				default:
+					route[routeKey] = routeValue
+				}
+			}
+		default:
+			result[key] = value
+		}
+	}
+
+	// dns 规则引用的 geosite-cn 需要在 rule_set 中声明
+	if dns, ok := result["dns"].(map[string]interface{}); ok && referencesRuleSet(dns["rules"], "geosite-cn") {
+		route := result["route"].(map[string]interface{})
+		ruleSets := route["rule_set"].([]interface{})
+		if !hasRuleSet(ruleSets, "geosite-cn") {
+			route["rule_set"] = append(ruleSets, singBoxRemoteRuleSet("geosite-cn", "binary", fmt.Sprintf(singBoxGeoSiteURL, "cn")))
+		}
+	}
+}
+
+// referencesRuleSet 判断规则中是否引用了 tag，rule_set 可以是字符串或数组
+func referencesRuleSet(rules interface{}, tag string) bool {
+	list, _ := rules.([]interface{})
+	for _, item := range list {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch ref := rule["rule_set"].(type) {
+		case string:
+			if ref == tag {
+				return true
+			}
+		case []interface{}:
+			for _, value := range ref {
+				if value == tag {
+					return true
+				}
+			}
+		case []string:
+			for _, value := range ref {
+				if value == tag {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasRuleSet 判断 rule_set 中是否已声明 tag
+func hasRuleSet(ruleSets []interface{}, tag string) bool {
+	for _, item := range ruleSets {
+		if ruleSet, ok := item.(map[string]interface{}); ok && ruleSet["tag"] == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalSingBox 输出缩进的 JSON，不转义 HTML 字符
+func marshalSingBox(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", fmt.Errorf("failed to marshal sing-box config: %v", err)
+	}
+	return buf.String(), nil
+}
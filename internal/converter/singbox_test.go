@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"encoding/json"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestSingBoxConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeSS, Name: "香港 01", Server: "hk.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass"},
+		{Type: model.TypeTrojan, Name: "日本 01", Server: "jp.example.com", Port: 443, Password: "pass", SNI: "jp.example.com"},
+		{Type: model.TypeSSR, Name: "SSR", Server: "ssr.example.com", Port: 443},
+	}
+	clashConfig := &config.ClashConfig{
+		ProxyGroups: []config.ProxyGroup{
+			{Name: "节点选择", Type: "select", Proxies: []string{"[]自动选择", "[]DIRECT", ".*"}},
+			{Name: "自动选择", Type: "url-test", Proxies: []string{"^(?!.*日本).*"}, URL: "http://www.gstatic.com/generate_204", Interval: 300, Tolerance: 50},
+			{Name: "美国节点", Type: "select", Proxies: []string{"美国"}},
+			{Name: "全球拦截", Type: "select", Proxies: []string{"[]REJECT", "[]DIRECT"}},
+		},
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "google.com", Strategy: "节点选择"},
+			{Type: "DOMAIN", Pararm: "www.google.com", Strategy: "节点选择"},
+			{Type: "DOMAIN-SUFFIX", Pararm: "ad.com", Strategy: "REJECT"},
+			{Type: "DOMAIN-SUFFIX", Pararm: "ads.com", Strategy: "全球拦截", Provider: "BanAD"},
+			{Type: "DOMAIN-KEYWORD", Pararm: "adservice", Strategy: "全球拦截", Provider: "BanAD"},
+			{Type: "DST-PORT", Pararm: "853", Strategy: "全球拦截", Provider: "BanAD"},
+			{Type: "RULE-SET", Pararm: "netflix", Strategy: "节点选择", Provider: "netflix"},
+			{Type: "RULE-SET", Pararm: "geoip", Strategy: "DIRECT", Provider: "geoip"},
+			{Type: "GEOIP", Pararm: "CN", Strategy: "DIRECT"},
+			{Type: "MATCH", Strategy: "节点选择"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "BanAD", URL: "https://example.com/BanAD.list", Format: config.FormatText},
+			{Name: "netflix", URL: "https://example.com/netflix.srs?raw=1", Format: config.FormatText},
+			{Name: "geoip", URL: "https://example.com/geoip.mrs", Format: config.FormatMRS},
+		},
+	}
+
+	result, err := NewSingBoxConverter(&BaseInfo{}).Convert(nodes, clashConfig)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	var got struct {
+		Outbounds []map[string]interface{} `json:"outbounds"`
+		Route     struct {
+			Rules   []map[string]interface{} `json:"rules"`
+			RuleSet []map[string]interface{} `json:"rule_set"`
+			Final   string                   `json:"final"`
+		} `json:"route"`
+	}
+	if err := json.Unmarshal([]byte(result), &got); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, result)
+	}
+
+	wantOutbounds := map[string][]interface{}{
+		"节点选择": {"自动选择", "DIRECT", "香港 01", "日本 01"},
+		"自动选择": {"香港 01"},
+		"美国节点": {"DIRECT"},
+		"全球拦截": {"DIRECT"},
+	}
+	tags := make([]string, 0)
+	for _, outbound := range got.Outbounds {
+		tag := outbound["tag"].(string)
+		tags = append(tags, tag)
+		if want, ok := wantOutbounds[tag]; ok && !reflect.DeepEqual(outbound["outbounds"], want) {
+			t.Errorf("group %s outbounds = %v, want %v", tag, outbound["outbounds"], want)
+		}
+	}
+	wantTags := []string{"节点选择", "自动选择", "美国节点", "全球拦截", "香港 01", "日本 01", "DIRECT"}
+	if !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("outbound tags = %v, want %v", tags, wantTags)
+	}
+	if got.Outbounds[1]["type"] != "urltest" || got.Outbounds[1]["interval"] != "300s" {
+		t.Errorf("url-test group = %v", got.Outbounds[1])
+	}
+
+	// sniff、hijack-dns 之后是转换的规则，mrs 规则集无法引用被跳过
+	rules := got.Route.Rules[2:]
+	if len(rules) != 5 {
+		t.Fatalf("rules = %v", rules)
+	}
+	if !reflect.DeepEqual(rules[0]["domain_suffix"], []interface{}{"google.com"}) ||
+		!reflect.DeepEqual(rules[0]["domain"], []interface{}{"www.google.com"}) {
+		t.Errorf("merged rule = %v", rules[0])
+	}
+	if rules[1]["action"] != "reject" {
+		t.Errorf("reject rule = %v", rules[1])
+	}
+	if rules[2]["rule_set"] != "BanAD" || rules[2]["action"] != "reject" {
+		t.Errorf("provider rule = %v", rules[2])
+	}
+	if rules[3]["rule_set"] != "netflix" || rules[3]["outbound"] != "节点选择" {
+		t.Errorf("remote rule_set rule = %v", rules[3])
+	}
+	if rules[4]["rule_set"] != "geoip-cn" || rules[4]["outbound"] != "DIRECT" {
+		t.Errorf("geoip rule = %v", rules[4])
+	}
+
+	ruleSets := make(map[string]map[string]interface{})
+	for _, ruleSet := range got.Route.RuleSet {
+		ruleSets[ruleSet["tag"].(string)] = ruleSet
+	}
+	if len(ruleSets) != 4 || ruleSets["geosite-cn"] == nil {
+		t.Errorf("rule_set = %v", got.Route.RuleSet)
+	}
+	wantInline := []interface{}{
+		map[string]interface{}{"domain_suffix": []interface{}{"ads.com"}, "domain_keyword": []interface{}{"adservice"}},
+		map[string]interface{}{"port": []interface{}{float64(853)}},
+	}
+	if ruleSets["BanAD"]["type"] != "inline" || !reflect.DeepEqual(ruleSets["BanAD"]["rules"], wantInline) {
+		t.Errorf("inline rule_set = %v", ruleSets["BanAD"])
+	}
+	if ruleSets["netflix"]["type"] != "remote" || ruleSets["netflix"]["format"] != "binary" {
+		t.Errorf("remote rule_set = %v", ruleSets["netflix"])
+	}
+	if got.Route.Final != "节点选择" {
+		t.Errorf("final = %s", got.Route.Final)
+	}
+}
+
+func TestSingBoxConvertWithoutGroups(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeSS, Name: "香港 01", Server: "hk.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass"},
+	}
+	clashConfig := &config.ClashConfig{
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "example.com", Strategy: "DIRECT"},
+			{Type: "MATCH", Strategy: "REJECT"},
+		},
+	}
+
+	result, err := NewSingBoxConverter(&BaseInfo{}).Convert(nodes, clashConfig)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	var got struct {
+		DNS struct {
+			Servers []map[string]interface{} `json:"servers"`
+		} `json:"dns"`
+		Route struct {
+			Rules []map[string]interface{} `json:"rules"`
+			Final string                   `json:"final"`
+		} `json:"route"`
+	}
+	if err := json.Unmarshal([]byte(result), &got); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, result)
+	}
+	if _, ok := got.DNS.Servers[0]["detour"]; ok {
+		t.Errorf("remote dns should not detour to DIRECT: %v", got.DNS.Servers[0])
+	}
+	last := got.Route.Rules[len(got.Route.Rules)-1]
+	if !reflect.DeepEqual(last, map[string]interface{}{"action": "reject"}) {
+		t.Errorf("MATCH,REJECT rule = %v", last)
+	}
+	if got.Route.Final != "DIRECT" {
+		t.Errorf("final = %s", got.Route.Final)
+	}
+}
+
+func TestSingBoxPluginOptsOrder(t *testing.T) {
+	node := &model.Node{
+		Type: model.TypeSS, Name: "ss", Server: "example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass",
+		Plugin: "kcptun", PluginOpts: map[string]string{"mode": "fast", "crypt": "aes", "key": "secret", "mtu": "1350"},
+	}
+	for i := 0; i < 10; i++ {
+		outbound, err := NewSingBoxConverter(&BaseInfo{}).ConvertNode(node)
+		if err != nil {
+			t.Fatalf("ConvertNode() error = %v", err)
+		}
+		if got := outbound.(map[string]interface{})["plugin_opts"]; got != "crypt=aes;key=secret;mode=fast;mtu=1350" {
+			t.Fatalf("plugin_opts = %v", got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// internal/subscription/converter/sip008.go
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/subscription/parser"
+	"log"
+	"strings"
+)
+
+// SIP008Converter 输出 SIP008 在线配置，只包含 SS 节点
+type SIP008Converter struct {
+	info *BaseInfo
+}
+
+func NewSIP008Converter(info *BaseInfo) *SIP008Converter {
+	return &SIP008Converter{
+		info: info,
+	}
+}
+
+// Convert SIP008 不包含策略组和规则，忽略外部配置
+func (c *SIP008Converter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	return c.ConvertList(nodes)
+}
+
+func (c *SIP008Converter) ConvertList(nodes []*model.Node) (string, error) {
+	data, skipped, err := parser.EncodeSIP008(nodes)
+	if err != nil {
+		return "", err
+	}
+
+	if len(skipped) > 0 {
+		names := make([]string, 0, len(skipped))
+		for _, node := range skipped {
+			names = append(names, node.Name+"("+string(node.Type)+")")
+		}
+		log.Printf("sip008 只支持 SS 节点，跳过 %d/%d 个节点: %s", len(skipped), len(nodes), strings.Join(names, ", "))
+	}
+
+	return string(data), nil
+}
+
+func (c *SIP008Converter) ConvertNode(node *model.Node) (interface{}, error) {
+	return parser.NewSIP008Server(node)
+}
+
+func (c *SIP008Converter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (c *SIP008Converter) FileName() string {
+	return "sip008.json"
+}
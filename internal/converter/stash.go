@@ -0,0 +1,169 @@
+// internal/subscription/converter/stash.go
+package converter
+
+import (
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"log"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// stashSupported Stash 支持的节点类型
+var stashSupported = map[model.NodeType]bool{
+	model.TypeSS:        true,
+	model.TypeSSR:       true,
+	model.TypeVmess:     true,
+	model.TypeVless:     true,
+	model.TypeTrojan:    true,
+	model.TypeHysteria2: true,
+	model.TypeTUIC:      true,
+	model.TypeWireGuard: true,
+	model.TypeSocks5:    true,
+	model.TypeHTTP:      true,
+}
+
+// stashBehaviors 纯文本规则集在 Stash 中对应的 behavior
+var stashBehaviors = map[string]string{
+	config.BehaviorDomain:    "domain-text",
+	config.BehaviorIPCIDR:    "ipcidr-text",
+	config.BehaviorClassical: "classical",
+}
+
+// StashConverter 在 Clash 配置的基础上适配 Stash 的字段
+type StashConverter struct {
+	clash *ClashConverter
+}
+
+func NewStashConverter(info *BaseInfo) *StashConverter {
+	return &StashConverter{
+		clash: NewClashConverter(info),
+	}
+}
+
+// SetRuleProviders 同 ClashConverter.SetRuleProviders
+func (s *StashConverter) SetRuleProviders(enable bool) {
+	s.clash.SetRuleProviders(enable)
+}
+
+func (s *StashConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
+	stashConfig, err := s.clash.buildConfig(s.supportedNodes(nodes), clashConfig)
+	if err != nil {
+		return "", err
+	}
+
+	for _, proxy := range stashConfig.Proxies {
+		s.adaptProxy(proxy)
+	}
+
+	// Stash 没有 format 字段，纯文本规则集使用 *-text behavior，不支持 mrs
+	for name, provider := range stashConfig.RuleProviders {
+		if provider.Format == config.FormatMRS {
+			log.Printf("stash 不支持 mrs 格式的规则集 %s", provider.URL)
+			delete(stashConfig.RuleProviders, name)
+			stashConfig.Rules = removeRuleSet(stashConfig.Rules, name)
+			continue
+		}
+		if provider.Format == config.FormatText {
+			provider.Behavior = stashBehaviors[provider.Behavior]
+		}
+		provider.Format = ""
+	}
+
+	data, err := yaml.Marshal(stashConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stash config: %v", err)
+	}
+
+	return string(data), nil
+}
+
+func (s *StashConverter) ConvertList(nodes []*model.Node) (string, error) {
+	proxies := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range s.supportedNodes(nodes) {
+		proxy, _ := s.ConvertNode(node)
+		proxies = append(proxies, proxy.(map[string]interface{}))
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"proxies": proxies})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stash proxies: %v", err)
+	}
+
+	return string(data), nil
+}
+
+func (s *StashConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+	proxy := node.ToClash()
+	s.adaptProxy(proxy)
+	return proxy, nil
+}
+
+func (s *StashConverter) ContentType() string {
+	return "text/yaml; charset=utf-8"
+}
+
+func (s *StashConverter) FileName() string {
+	return "stash.yaml"
+}
+
+// checkNode 检查节点是否能被 Stash 加载
+func (s *StashConverter) checkNode(node *model.Node) error {
+	if !stashSupported[node.Type] {
+		return fmt.Errorf("node type %s is not supported by stash", node.Type)
+	}
+	if node.Type == model.TypeSS && node.Plugin != "" && node.Plugin != "obfs" && node.Plugin != "v2ray-plugin" {
+		return fmt.Errorf("ss plugin %s is not supported by stash", node.Plugin)
+	}
+	return nil
+}
+
+// supportedNodes 过滤掉 Stash 不支持的节点
+func (s *StashConverter) supportedNodes(nodes []*model.Node) []*model.Node {
+	supported := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if err := s.checkNode(node); err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			continue
+		}
+		supported = append(supported, node)
+	}
+	return supported
+}
+
+// adaptProxy 将 mihomo 字段改写为 Stash 的写法
+func (s *StashConverter) adaptProxy(proxy map[string]interface{}) {
+	switch proxy["type"] {
+	case "hysteria2":
+		// Stash 使用 auth、up-speed、down-speed
+		renameKey(proxy, "password", "auth")
+		renameKey(proxy, "up", "up-speed")
+		renameKey(proxy, "down", "down-speed")
+	case "tuic":
+		proxy["version"] = 5
+	}
+}
+
+func renameKey(m map[string]interface{}, from, to string) {
+	if value, found := m[from]; found {
+		delete(m, from)
+		m[to] = value
+	}
+}
+
+// removeRuleSet 删除引用指定规则集的 RULE-SET 规则
+func removeRuleSet(rules []string, name string) []string {
+	kept := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if strings.HasPrefix(rule, "RULE-SET,"+name+",") {
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	return kept
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestStashConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeHysteria2, Name: "HY2", Server: "hy2.example.com", Port: 443, Password: "pass", Up: "50", Down: "100"},
+		{Type: model.TypeTUIC, Name: "TUIC", Server: "tuic.example.com", Port: 443, UUID: "uuid", Password: "pass"},
+		{Type: model.TypeAnyTLS, Name: "AnyTLS", Server: "anytls.example.com", Port: 443, Password: "pass"},
+	}
+	clashConfig := &config.ClashConfig{
+		ProxyGroups: []config.ProxyGroup{
+			{Name: "节点选择", Type: "select", Proxies: []string{".*"}},
+		},
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "cn", Strategy: "DIRECT", Provider: "cn"},
+			{Type: "RULE-SET", Pararm: "geoip", Strategy: "DIRECT", Provider: "geoip"},
+			{Type: "MATCH", Strategy: "节点选择"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "cn", Behavior: config.BehaviorDomain, Format: config.FormatText, URL: "https://example.com/cn.txt"},
+			{Name: "geoip", Behavior: config.BehaviorIPCIDR, Format: config.FormatMRS, URL: "https://example.com/geoip.mrs"},
+		},
+	}
+
+	conv := NewStashConverter(&BaseInfo{})
+	conv.SetRuleProviders(true)
+	result, err := conv.Convert(nodes, clashConfig)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"auth: pass", "up-speed: 50", "down-speed: 100", "version: 5",
+		"behavior: domain-text", "- RULE-SET,cn,DIRECT", "- MATCH,节点选择",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q:\n%s", want, result)
+		}
+	}
+	for _, unwanted := range []string{"AnyTLS", "geoip", "format:"} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("result should not contain %q:\n%s", unwanted, result)
+		}
+	}
+}
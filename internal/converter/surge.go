@@ -3,23 +3,85 @@ package converter
 
 import (
 	"fmt"
+	"goconverter/internal/config"
 	"goconverter/internal/subscription/model"
+	"log"
+	"net"
+	"strconv"
 	"strings"
 )
 
+// 默认的 Surge 版本，TUIC 与 Hysteria2 需要 Surge 5
+const defaultSurgeVersion = 5
+
+// surgeMinVersion 各类节点最低支持的 Surge 版本
+var surgeMinVersion = map[model.NodeType]int{
+	model.TypeSS:        3,
+	model.TypeSocks5:    3,
+	model.TypeHTTP:      3,
+	model.TypeVmess:     4,
+	model.TypeTrojan:    4,
+	model.TypeWireGuard: 4,
+	model.TypeHysteria2: 5,
+	model.TypeTUIC:      5,
+}
+
+// surgeRuleTypes Clash 规则类型对应的 Surge 规则类型
+var surgeRuleTypes = map[string]string{
+	"DOMAIN":         "DOMAIN",
+	"DOMAIN-SUFFIX":  "DOMAIN-SUFFIX",
+	"DOMAIN-KEYWORD": "DOMAIN-KEYWORD",
+	"IP-CIDR":        "IP-CIDR",
+	"IP-CIDR6":       "IP-CIDR6",
+	"GEOIP":          "GEOIP",
+	"SRC-IP-CIDR":    "SRC-IP",
+	"DST-PORT":       "DEST-PORT",
+	"SRC-PORT":       "SRC-PORT",
+	"PROCESS-NAME":   "PROCESS-NAME",
+	"USER-AGENT":     "USER-AGENT",
+	"URL-REGEX":      "URL-REGEX",
+	"RULE-SET":       "RULE-SET",
+}
+
 type SurgeConverter struct {
-	info BaseInfo
+	info       BaseInfo
+	version    int    // 目标 Surge 版本：3/4/5
+	managedURL string // 托管配置地址，为空时不输出 #!MANAGED-CONFIG
+	interval   int    // 托管配置更新间隔(秒)
 }
 
 func NewSurgeConverter(info BaseInfo) *SurgeConverter {
 	return &SurgeConverter{
-		info: info,
+		info:     info,
+		version:  defaultSurgeVersion,
+		interval: 86400,
 	}
 }
 
-func (s *SurgeConverter) Convert(nodes []*model.Node) (string, error) {
+// SetVersion 设置目标 Surge 版本，对应 subconverter 的 ver 参数
+func (s *SurgeConverter) SetVersion(version int) error {
+	if version < 3 || version > 5 {
+		return fmt.Errorf("unsupported surge version: %d", version)
+	}
+	s.version = version
+	return nil
+}
+
+// SetManagedConfig 设置托管配置地址与更新间隔
+func (s *SurgeConverter) SetManagedConfig(url string, interval int) {
+	s.managedURL = url
+	if interval > 0 {
+		s.interval = interval
+	}
+}
+
+func (s *SurgeConverter) Convert(nodes []*model.Node, clashConfig *config.ClashConfig) (string, error) {
 	var builder strings.Builder
 
+	if s.managedURL != "" {
+		builder.WriteString(fmt.Sprintf("#!MANAGED-CONFIG %s interval=%d strict=false\n\n", s.managedURL, s.interval))
+	}
+
 	// 写入基础配置
 	builder.WriteString("[General]\n")
 	builder.WriteString("loglevel = notify\n")
@@ -32,97 +94,314 @@ func (s *SurgeConverter) Convert(nodes []*model.Node) (string, error) {
 	builder.WriteString("[Proxy]\n")
 	builder.WriteString("DIRECT = direct\n")
 
+	converted := make([]*model.Node, 0, len(nodes))
 	for _, node := range nodes {
 		proxy, err := s.ConvertNode(node)
 		if err != nil {
-			return "", err
-		}
-		if proxyStr, ok := proxy.(string); ok {
-			builder.WriteString(proxyStr + "\n")
+			// 跳过的节点以注释形式保留在输出中
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			builder.WriteString(fmt.Sprintf("# 跳过节点 %s: %v\n", node.Name, err))
+			continue
 		}
+		builder.WriteString(proxy.(string) + "\n")
+		converted = append(converted, node)
 	}
 
 	// 写入代理组
 	builder.WriteString("\n[Proxy Group]\n")
-	builder.WriteString("🚀 节点选择 = select,DIRECT")
-	for _, node := range nodes {
-		builder.WriteString("," + node.Name)
+	for _, group := range clashConfig.ProxyGroups {
+		line, err := s.getProxyGroup(group, converted)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(line + "\n")
 	}
-	builder.WriteString("\n\n")
+	builder.WriteString("\n")
 
 	// 写入规则
 	builder.WriteString("[Rule]\n")
-	for _, rule := range s.getRules() {
+	for _, rule := range s.getRules(clashConfig) {
 		builder.WriteString(rule + "\n")
 	}
 
+	// 写入 WireGuard 配置段
+	for _, node := range converted {
+		if node.Type == model.TypeWireGuard {
+			builder.WriteString("\n" + s.getWireGuardSection(node))
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func (s *SurgeConverter) ConvertList(nodes []*model.Node) (string, error) {
+	var builder strings.Builder
+	for _, node := range nodes {
+		proxy, err := s.ConvertNode(node)
+		if err != nil {
+			log.Printf("跳过节点 %s: %v", node.Name, err)
+			builder.WriteString(fmt.Sprintf("# 跳过节点 %s: %v\n", node.Name, err))
+			continue
+		}
+		builder.WriteString(proxy.(string) + "\n")
+	}
 	return builder.String(), nil
 }
 
+func (s *SurgeConverter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (s *SurgeConverter) FileName() string {
+	return "surge.conf"
+}
+
 func (s *SurgeConverter) ConvertNode(node *model.Node) (interface{}, error) {
+	if minVersion, ok := surgeMinVersion[node.Type]; !ok || s.version < minVersion {
+		return "", fmt.Errorf("node type %s is not supported by surge %d", node.Type, s.version)
+	}
+
 	switch node.Type {
 	case model.TypeSS:
-		return fmt.Sprintf("%s = ss, %s, %d, encrypt-method=%s, password=%s%s",
-			node.Name, node.Server, node.Port, node.Cipher, node.Password,
-			s.getSSPluginOpts(node)), nil
+		opts, err := s.getSSPluginOpts(node)
+		if err != nil {
+			return "", err
+		}
+		line := fmt.Sprintf("%s = ss, %s, %d, encrypt-method=%s, password=%s%s",
+			node.Name, node.Server, node.Port, node.Cipher, node.Password, opts)
+		if node.UDP {
+			line += ", udp-relay=true"
+		}
+		return line, nil
 
 	case model.TypeTrojan:
-		return fmt.Sprintf("%s = trojan, %s, %d, password=%s, sni=%s, skip-cert-verify=%v",
+		transport, err := s.getTransportOpts(node)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = trojan, %s, %d, password=%s, sni=%s, skip-cert-verify=%v%s",
 			node.Name, node.Server, node.Port, node.Password,
-			defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure), nil
+			defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure, transport), nil
 
 	case model.TypeVmess:
-		return fmt.Sprintf("%s = vmess, %s, %d, username=%s, ws=%v, tls=%v%s",
-			node.Name, node.Server, node.Port, node.UUID,
-			node.Network == "ws", node.TLS,
-			s.getVmessOpts(node)), nil
+		transport, err := s.getTransportOpts(node)
+		if err != nil {
+			return "", err
+		}
+		line := fmt.Sprintf("%s = vmess, %s, %d, username=%s, tls=%v%s",
+			node.Name, node.Server, node.Port, node.UUID, node.TLS, transport)
+		if node.TLS {
+			line += fmt.Sprintf(", sni=%s, skip-cert-verify=%v", defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure)
+		}
+		if node.AlterID == 0 {
+			line += ", vmess-aead=true"
+		}
+		return line, nil
+
+	case model.TypeHysteria2:
+		if node.Obfs != "" {
+			return "", fmt.Errorf("hysteria2 obfs %s is not supported by surge", node.Obfs)
+		}
+		line := fmt.Sprintf("%s = hysteria2, %s, %d, password=%s, sni=%s, skip-cert-verify=%v",
+			node.Name, node.Server, node.Port, node.Password,
+			defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure)
+		if down, err := strconv.Atoi(node.Down); err == nil {
+			line += fmt.Sprintf(", download-bandwidth=%d", down)
+		}
+		return line, nil
+
+	case model.TypeTUIC:
+		return fmt.Sprintf("%s = tuic-v5, %s, %d, password=%s, uuid=%s, alpn=%s, sni=%s, skip-cert-verify=%v",
+			node.Name, node.Server, node.Port, node.Password, node.UUID,
+			defaultIfEmpty(strings.Join(node.ALPN, ","), "h3"),
+			defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure), nil
+
+	case model.TypeSocks5, model.TypeHTTP:
+		proxyType := string(node.Type)
+		if node.TLS {
+			proxyType = map[model.NodeType]string{model.TypeSocks5: "socks5-tls", model.TypeHTTP: "https"}[node.Type]
+		}
+		line := fmt.Sprintf("%s = %s, %s, %d", node.Name, proxyType, node.Server, node.Port)
+		if node.Username != "" {
+			line += fmt.Sprintf(", username=%s, password=%s", node.Username, node.Password)
+		}
+		if node.TLS {
+			line += fmt.Sprintf(", sni=%s, skip-cert-verify=%v", defaultIfEmpty(node.SNI, node.Server), node.AllowInsecure)
+		}
+		return line, nil
+
+	case model.TypeWireGuard:
+		return fmt.Sprintf("%s = wireguard, section-name = %s", node.Name, node.Name), nil
 
 	default:
 		return "", fmt.Errorf("unsupported node type: %s", node.Type)
 	}
 }
 
-func (s *SurgeConverter) getSSPluginOpts(node *model.Node) string {
-	if node.Plugin == "" {
-		return ""
-	}
-	opts := []string{fmt.Sprintf(", plugin=%s", node.Plugin)}
-	for k, v := range node.PluginOpts {
-		opts = append(opts, fmt.Sprintf("%s=%s", k, v))
+// getSSPluginOpts 生成 SS 混淆参数，Surge 只支持 simple-obfs
+func (s *SurgeConverter) getSSPluginOpts(node *model.Node) (string, error) {
+	switch node.Plugin {
+	case "":
+		return "", nil
+	case "obfs", "obfs-local", "simple-obfs":
+		opts := fmt.Sprintf(", obfs=%s", defaultIfEmpty(node.PluginOpts["mode"], "http"))
+		if host := node.PluginOpts["host"]; host != "" {
+			opts += fmt.Sprintf(", obfs-host=%s", host)
+		}
+		return opts, nil
+	default:
+		return "", fmt.Errorf("ss plugin %s is not supported by surge", node.Plugin)
 	}
-	return strings.Join(opts, ",")
 }
 
-func (s *SurgeConverter) getVmessOpts(node *model.Node) string {
-	var opts []string
-	if node.Network == "ws" {
+// getTransportOpts 生成 vmess/trojan 的 WebSocket 参数
+func (s *SurgeConverter) getTransportOpts(node *model.Node) (string, error) {
+	switch node.Network {
+	case "", "tcp":
+		return "", nil
+	case "ws":
+		opts := []string{"ws=true"}
 		if node.WsPath != "" {
 			opts = append(opts, fmt.Sprintf("ws-path=%s", node.WsPath))
 		}
 		if len(node.WsHeaders) > 0 {
+			headers := make([]string, 0, len(node.WsHeaders))
 			for k, v := range node.WsHeaders {
-				opts = append(opts, fmt.Sprintf("ws-headers=%s:%s", k, v))
+				headers = append(headers, fmt.Sprintf("%s:%s", k, v))
 			}
+			opts = append(opts, fmt.Sprintf("ws-headers=%s", strings.Join(headers, "|")))
 		}
+		return ", " + strings.Join(opts, ", "), nil
+	default:
+		return "", fmt.Errorf("transport %s is not supported by surge", node.Network)
 	}
-	if node.TLS {
-		opts = append(opts, fmt.Sprintf("sni=%s", defaultIfEmpty(node.SNI, node.Server)))
+}
+
+// getProxyGroup 生成 [Proxy Group] 中的一行
+func (s *SurgeConverter) getProxyGroup(group config.ProxyGroup, nodes []*model.Node) (string, error) {
+	proxies, err := groupProxies(group, nodes)
+	if err != nil {
+		return "", err
 	}
-	if len(opts) > 0 {
-		return ", " + strings.Join(opts, ", ")
+
+	groupType := group.Type
+	if groupType == "load-balance" && s.version < 4 {
+		log.Printf("surge %d 不支持 load-balance，策略组 %s 改为 url-test", s.version, group.Name)
+		groupType = "url-test"
 	}
-	return ""
+
+	parts := append([]string{groupType}, proxies...)
+	if groupType != "select" {
+		if group.URL != "" {
+			parts = append(parts, fmt.Sprintf("url=%s", group.URL))
+		}
+		if group.Interval > 0 {
+			parts = append(parts, fmt.Sprintf("interval=%d", group.Interval))
+		}
+		if group.Timeout > 0 {
+			parts = append(parts, fmt.Sprintf("timeout=%d", group.Timeout))
+		}
+		if group.Tolerance > 0 && groupType == "url-test" {
+			parts = append(parts, fmt.Sprintf("tolerance=%d", group.Tolerance))
+		}
+	}
+
+	return fmt.Sprintf("%s = %s", group.Name, strings.Join(parts, ", ")), nil
 }
 
-func (s *SurgeConverter) getRules() []string {
-	return []string{
-		"DOMAIN-SUFFIX,google.com,🚀 节点选择",
-		"DOMAIN-KEYWORD,google,🚀 节点选择",
-		"DOMAIN-SUFFIX,ad.com,REJECT",
-		"DOMAIN-KEYWORD,facebook,🚀 节点选择",
-		"DOMAIN-KEYWORD,youtube,🚀 节点选择",
-		"FINAL,🚀 节点选择",
+// getRules 生成 [Rule]，远程规则集输出为 RULE-SET/DOMAIN-SET 引用
+func (s *SurgeConverter) getRules(clashConfig *config.ClashConfig) []string {
+	providers := make(map[string]config.RuleProvider)
+	for _, provider := range clashConfig.RuleProviders {
+		providers[provider.Name] = provider
 	}
+
+	rules := make([]string, 0)
+	referenced := make(map[string]bool)
+	final := ""
+	for _, ruleset := range clashConfig.RuleSets {
+		if provider, found := providers[ruleset.Provider]; found {
+			ruleType := ""
+			if provider.Format == config.FormatText && provider.Behavior == config.BehaviorClassical {
+				ruleType = "RULE-SET"
+			} else if provider.Format == config.FormatText && provider.Behavior == config.BehaviorDomain {
+				ruleType = "DOMAIN-SET"
+			}
+
+			if ruleType != "" {
+				if !referenced[provider.Name] {
+					referenced[provider.Name] = true
+					rules = append(rules, strings.Join([]string{ruleType, provider.URL, ruleset.Strategy}, ","))
+				}
+				continue
+			}
+			if ruleset.Type == "RULE-SET" {
+				log.Printf("surge 不支持 %s 格式的规则集 %s", provider.Format, provider.URL)
+				continue
+			}
+		}
+
+		if ruleset.Type == "MATCH" || ruleset.Type == "FINAL" {
+			final = ruleset.Strategy
+			continue
+		}
+		ruleType, ok := surgeRuleTypes[ruleset.Type]
+		if !ok || (ruleset.Type == "GEOIP" && strings.EqualFold(ruleset.Pararm, "LAN")) {
+			continue
+		}
+
+		parts := []string{ruleType, ruleset.Pararm, ruleset.Strategy}
+		if ruleset.NoResolve != "" {
+			parts = append(parts, ruleset.NoResolve)
+		}
+		rules = append(rules, strings.Join(parts, ","))
+	}
+
+	// Surge 要求以 FINAL 结尾
+	if final == "" {
+		final = "DIRECT"
+		if len(clashConfig.ProxyGroups) > 0 {
+			final = clashConfig.ProxyGroups[0].Name
+		}
+	}
+	rules = append(rules, "FINAL,"+final)
+
+	return rules
+}
+
+// getWireGuardSection 生成 [WireGuard name] 配置段
+func (s *SurgeConverter) getWireGuardSection(node *model.Node) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("[WireGuard %s]\n", node.Name))
+	builder.WriteString(fmt.Sprintf("private-key = %s\n", node.PrivateKey))
+	if node.IP != "" {
+		builder.WriteString(fmt.Sprintf("self-ip = %s\n", node.IP))
+	}
+	if node.IPv6 != "" {
+		builder.WriteString(fmt.Sprintf("self-ip-v6 = %s\n", node.IPv6))
+	}
+	if node.MTU > 0 {
+		builder.WriteString(fmt.Sprintf("mtu = %d\n", node.MTU))
+	}
+
+	peer := []string{
+		fmt.Sprintf("public-key = %s", node.PublicKey),
+		fmt.Sprintf("allowed-ips = \"%s\"", defaultIfEmpty(strings.Join(node.AllowedIPs, ", "), "0.0.0.0/0, ::/0")),
+		fmt.Sprintf("endpoint = %s", net.JoinHostPort(node.Server, strconv.Itoa(node.Port))),
+	}
+	if node.PreSharedKey != "" {
+		peer = append(peer, fmt.Sprintf("preshared-key = %s", node.PreSharedKey))
+	}
+	if len(node.Reserved) > 0 {
+		ids := make([]string, 0, len(node.Reserved))
+		for _, b := range node.Reserved {
+			ids = append(ids, strconv.Itoa(b))
+		}
+		peer = append(peer, fmt.Sprintf("client-id = %s", strings.Join(ids, "/")))
+	}
+	builder.WriteString(fmt.Sprintf("peer = (%s)\n", strings.Join(peer, ", ")))
+
+	return builder.String()
 }
 
 // 工具函数
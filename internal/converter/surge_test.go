@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"goconverter/internal/config"
+	"goconverter/internal/subscription/model"
+	"strings"
+	"testing"
+)
+
+func TestSurgeConvert(t *testing.T) {
+	nodes := []*model.Node{
+		{
+			Type:         model.TypeWireGuard,
+			Name:         "wg",
+			Server:       "wg.example.com",
+			Port:         51820,
+			PrivateKey:   "privateKey=",
+			PublicKey:    "publicKey=",
+			PreSharedKey: "psk",
+			IP:           "10.0.0.2",
+			MTU:          1280,
+			Reserved:     []int{1, 2, 3},
+		},
+		{
+			Type:     model.TypeSocks5,
+			Name:     "corp",
+			Server:   "10.0.0.1",
+			Port:     1080,
+			Username: "user",
+			Password: "pass",
+		},
+		{
+			Type:   model.TypeHTTP,
+			Name:   "https",
+			Server: "proxy.example.com",
+			Port:   443,
+			TLS:    true,
+		},
+	}
+
+	result, err := NewSurgeConverter(BaseInfo{}).Convert(nodes, &config.ClashConfig{})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"wg = wireguard, section-name = wg\n",
+		"corp = socks5, 10.0.0.1, 1080, username=user, password=pass\n",
+		"https = https, proxy.example.com, 443, sni=proxy.example.com, skip-cert-verify=false\n",
+		"[WireGuard wg]\nprivate-key = privateKey=\nself-ip = 10.0.0.2\nmtu = 1280\n",
+		`peer = (public-key = publicKey=, allowed-ips = "0.0.0.0/0, ::/0", endpoint = wg.example.com:51820, preshared-key = psk, client-id = 1/2/3)`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q:\n%s", want, result)
+		}
+	}
+}
+
+func TestSurgeConvertConfig(t *testing.T) {
+	nodes := []*model.Node{
+		{
+			Type: model.TypeSS, Name: "香港 01", Server: "hk.example.com", Port: 8388, Cipher: "aes-128-gcm", Password: "pass",
+			Plugin: "obfs", PluginOpts: map[string]string{"mode": "tls", "host": "bing.com"},
+		},
+		{Type: model.TypeTUIC, Name: "日本 TUIC", Server: "jp.example.com", Port: 443, UUID: "uuid", Password: "pass"},
+		{Type: model.TypeVless, Name: "美国 VLESS", Server: "us.example.com", Port: 443, UUID: "uuid"},
+	}
+	clashConfig := &config.ClashConfig{
+		ProxyGroups: []config.ProxyGroup{
+			{Name: "节点选择", Type: "select", Proxies: []string{"[]自动选择", ".*"}},
+			{Name: "自动选择", Type: "url-test", Proxies: []string{".*"}, URL: "http://www.gstatic.com/generate_204", Interval: 300, Timeout: 5, Tolerance: 50},
+		},
+		RuleSets: []config.ClashRule{
+			{Type: "DOMAIN-SUFFIX", Pararm: "ad.com", Strategy: "REJECT", Source: "https://example.com/BanAD.list", Provider: "BanAD"},
+			{Type: "DOMAIN-KEYWORD", Pararm: "ads", Strategy: "REJECT", Source: "https://example.com/BanAD.list", Provider: "BanAD"},
+			{Type: "DST-PORT", Pararm: "853", Strategy: "REJECT"},
+			{Type: "GEOIP", Pararm: "CN", Strategy: "DIRECT"},
+			{Type: "MATCH", Strategy: "节点选择"},
+		},
+		RuleProviders: []config.RuleProvider{
+			{Name: "BanAD", Behavior: config.BehaviorClassical, Format: config.FormatText, URL: "https://example.com/BanAD.list"},
+		},
+	}
+
+	tests := []struct {
+		version  int
+		want     []string
+		unwanted []string
+	}{
+		{
+			version: 4,
+			want: []string{
+				"#!MANAGED-CONFIG https://sub.example.com/sub?target=surge interval=43200 strict=false\n",
+				"香港 01 = ss, hk.example.com, 8388, encrypt-method=aes-128-gcm, password=pass, obfs=tls, obfs-host=bing.com\n",
+				"节点选择 = select, 自动选择, 香港 01\n",
+				"自动选择 = url-test, 香港 01, url=http://www.gstatic.com/generate_204, interval=300, timeout=5, tolerance=50\n",
+				"[Rule]\nRULE-SET,https://example.com/BanAD.list,REJECT\nDEST-PORT,853,REJECT\nGEOIP,CN,DIRECT\nFINAL,节点选择\n",
+				"# 跳过节点 日本 TUIC: node type tuic is not supported by surge 4\n",
+			},
+			unwanted: []string{"tuic-v5", "DOMAIN-KEYWORD,ads"},
+		},
+		{
+			version: 5,
+			want:    []string{"日本 TUIC = tuic-v5, jp.example.com, 443", "节点选择 = select, 自动选择, 香港 01, 日本 TUIC\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		conv := NewSurgeConverter(BaseInfo{})
+		if err := conv.SetVersion(tt.version); err != nil {
+			t.Fatalf("SetVersion() error = %v", err)
+		}
+		conv.SetManagedConfig("https://sub.example.com/sub?target=surge", 43200)
+		result, err := conv.Convert(nodes, clashConfig)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(result, want) {
+				t.Errorf("surge %d result missing %q:\n%s", tt.version, want, result)
+			}
+		}
+		for _, unwanted := range tt.unwanted {
+			if strings.Contains(result, unwanted) {
+				t.Errorf("surge %d result should not contain %q:\n%s", tt.version, unwanted, result)
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// internal/fetcher/cache.go
+package fetcher
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry 缓存的响应
+type CacheEntry struct {
+	URL          string      `json:"url"`
+	Body         []byte      `json:"body"`
+	Header       http.Header `json:"header,omitempty"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	FetchedAt    time.Time   `json:"fetched_at"` // 最近一次下载或验证的时间
+}
+
+// CacheStats 缓存命中统计
+type CacheStats struct {
+	Hits        int64 // TTL 内直接返回
+	Revalidated int64 // 条件请求返回 304
+	Misses      int64 // 重新下载
+	Stale       int64 // 上游失败时返回过期内容
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("命中 %d, 未修改 %d, 未命中 %d, 过期返回 %d", s.Hits, s.Revalidated, s.Misses, s.Stale)
+}
+
+// defaultCacheMaxSize 内存缓存的默认容量
+const defaultCacheMaxSize = 128 << 20
+
+// Cache 响应缓存，保存在内存中，指定目录时同时写入磁盘
+//
+// 内存中的响应体总大小超过容量时淘汰最久未使用的条目，磁盘上的缓存不受影响。
+type Cache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element // URL -> *CacheEntry
+	order      *list.List               // 最近使用的在前
+	size       int64                    // 内存中响应体的总大小
+	maxSize    int64
+	dir        string
+	defaultTTL time.Duration
+	ttls       map[string]time.Duration // URL 前缀 -> TTL
+	stats      CacheStats
+}
+
+// NewCache 创建缓存，dir 为空时只使用内存
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxSize:    defaultCacheMaxSize,
+		dir:        dir,
+		defaultTTL: ttl,
+		ttls:       make(map[string]time.Duration),
+	}
+}
+
+// SetTTL 为指定前缀的 URL 设置 TTL，多个前缀匹配时最长的优先
+func (c *Cache) SetTTL(prefix string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttls[prefix] = ttl
+}
+
+// SetMaxSize 设置内存缓存的容量(字节)，不大于 0 时不限制
+func (c *Cache) SetMaxSize(size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxSize = size
+	c.evict()
+}
+
+// TTL 返回 URL 对应的 TTL
+func (c *Cache) TTL(url string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ttl, matched := c.defaultTTL, ""
+	for prefix, value := range c.ttls {
+		if strings.HasPrefix(url, prefix) && len(prefix) > len(matched) {
+			ttl, matched = value, prefix
+		}
+	}
+	return ttl
+}
+
+// Stats 返回命中统计
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Get 读取缓存，内存中没有时尝试从磁盘加载
+func (c *Cache) Get(url string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[url]; found {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*CacheEntry), true
+	}
+	if c.dir == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil, false
+	}
+	c.store(&entry)
+	return &entry, true
+}
+
+// Put 保存缓存，写入磁盘失败只记录日志
+func (c *Cache) Put(entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(entry)
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(c.dir, 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(c.path(entry.URL), data, 0644)
+	}
+	if err != nil {
+		log.Printf("写入缓存 %s 失败: %v", entry.URL, err)
+	}
+}
+
+// store 保存到内存并淘汰超出容量的条目，调用方需持有锁
+func (c *Cache) store(entry *CacheEntry) {
+	if elem, found := c.entries[entry.URL]; found {
+		c.size -= int64(len(elem.Value.(*CacheEntry).Body))
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[entry.URL] = c.order.PushFront(entry)
+	}
+	c.size += int64(len(entry.Body))
+	c.evict()
+}
+
+// evict 淘汰最久未使用的条目直到不超过容量，至少保留最新的一条
+func (c *Cache) evict() {
+	for c.maxSize > 0 && c.size > c.maxSize && c.order.Len() > 1 {
+		elem := c.order.Back()
+		entry := c.order.Remove(elem).(*CacheEntry)
+		delete(c.entries, entry.URL)
+		c.size -= int64(len(entry.Body))
+	}
+}
+
+// fresh 判断缓存是否仍在 TTL 内
+func (c *Cache) fresh(entry *CacheEntry) bool {
+	return time.Since(entry.FetchedAt) < c.TTL(entry.URL)
+}
+
+// cacheResult 一次请求的缓存结果
+type cacheResult int
+
+const (
+	cacheHit cacheResult = iota
+	cacheRevalidated
+	cacheMiss
+	cacheStale
+)
+
+// record 记录一次缓存结果
+func (c *Cache) record(url string, result cacheResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch result {
+	case cacheHit:
+		c.stats.Hits++
+		log.Printf("缓存命中: %s", url)
+	case cacheRevalidated:
+		c.stats.Revalidated++
+		log.Printf("缓存未修改: %s", url)
+	case cacheMiss:
+		c.stats.Misses++
+		log.Printf("缓存未命中: %s", url)
+	case cacheStale:
+		c.stats.Stale++
+		log.Printf("上游不可用，使用过期缓存: %s", url)
+	}
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
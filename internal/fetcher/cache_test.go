@@ -0,0 +1,91 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchCache(t *testing.T) {
+	requests, failing := 0, false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failing {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("content " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Hour)
+	cache.SetTTL(upstream.URL+"/short", 0)
+	f := NewFetcher(WithCache(cache), WithRetry(0, 0))
+
+	fetch := func(path, want string) {
+		t.Helper()
+		body, err := f.Fetch(upstream.URL + path)
+		if err != nil {
+			t.Fatalf("Fetch(%s) error = %v", path, err)
+		}
+		if string(body) != want {
+			t.Fatalf("Fetch(%s) = %q, want %q", path, body, want)
+		}
+	}
+
+	fetch("/long", "content /long")   // 未命中
+	fetch("/long", "content /long")   // TTL 内命中
+	fetch("/short", "content /short") // 未命中
+	fetch("/short", "content /short") // TTL 为 0，条件请求返回 304
+	failing = true
+	fetch("/short", "content /short") // 上游失败，返回过期缓存
+	if _, err := f.Fetch(upstream.URL + "/missing"); err == nil {
+		t.Error("Fetch() should fail without cache")
+	}
+
+	want := CacheStats{Hits: 1, Revalidated: 1, Misses: 2, Stale: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if requests != 5 {
+		t.Errorf("upstream requests = %d, want 5", requests)
+	}
+
+	// 磁盘缓存在新的实例中仍然可用
+	f.SetCache(NewCache(dir, time.Hour))
+	fetch("/long", "content /long")
+	if got := f.Cache().Stats(); got.Hits != 1 {
+		t.Errorf("disk cache Stats() = %+v", got)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache := NewCache("", time.Hour)
+	cache.SetMaxSize(10)
+	cache.Put(&CacheEntry{URL: "a", Body: []byte("aaaa")})
+	cache.Put(&CacheEntry{URL: "b", Body: []byte("bbbb")})
+	cache.Get("a") // a 最近使用，b 先被淘汰
+	cache.Put(&CacheEntry{URL: "c", Body: []byte("cccc")})
+
+	for url, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, found := cache.Get(url); found != want {
+			t.Errorf("Get(%s) found = %v, want %v", url, found, want)
+		}
+	}
+
+	// 超过容量的单个条目仍然保留，其余条目被淘汰
+	cache.Put(&CacheEntry{URL: "d", Body: []byte("dddddddddddd")})
+	if _, found := cache.Get("a"); found {
+		t.Error("Get(a) should be evicted")
+	}
+	if _, found := cache.Get("d"); !found {
+		t.Error("Get(d) should keep the newest entry")
+	}
+}
@@ -2,37 +2,293 @@
 package fetcher
 
 import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
 	"time"
+
+	"github.com/andybalholm/brotli"
 )
 
+// ErrBodyTooLarge 响应体超过 WithMaxBodySize 设置的大小
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// StatusError 上游返回了非 2xx 状态码
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s from %s", e.Status, e.URL)
+}
+
+// Temporary 429 与 5xx 视为临时错误，可以重试
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
 type Fetcher struct {
-	client *http.Client
+	client      *http.Client
+	transport   *http.Transport
+	cache       *Cache
+	timeout     time.Duration
+	retries     int
+	retryDelay  time.Duration
+	maxBodySize int64
+	userAgent   string
+	headers     http.Header
+	baseDir     string // 本地相对路径的基准目录
+	localFiles  bool   // 是否允许读取本地文件
 }
 
-func NewFetcher() *Fetcher {
-	return &Fetcher{
-		client: &http.Client{
-			Timeout: 20 * time.Second,
-		},
+func NewFetcher(opts ...Option) *Fetcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	f := &Fetcher{
+		client:      &http.Client{Transport: transport},
+		transport:   transport,
+		timeout:     defaultTimeout,
+		retries:     defaultRetries,
+		retryDelay:  defaultRetryDelay,
+		maxBodySize: defaultMaxBodySize,
+		userAgent:   defaultUserAgent,
+		headers:     make(http.Header),
+		localFiles:  true,
+	}
+	for _, opt := range opts {
+		opt(f)
 	}
+	return f
 }
 
+// SetCache 启用响应缓存，nil 表示不缓存
+func (f *Fetcher) SetCache(cache *Cache) {
+	f.cache = cache
+}
+
+// Cache 返回当前使用的缓存
+func (f *Fetcher) Cache() *Cache {
+	return f.cache
+}
+
+// Response 下载结果
+type Response struct {
+	Body   []byte
+	Header http.Header // 响应头，命中缓存时为缓存的响应头
+}
+
+// Fetch 下载内容，等同于 FetchContext(context.Background(), url)
 func (f *Fetcher) Fetch(url string) ([]byte, error) {
+	return f.FetchContext(context.Background(), url)
+}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// FetchContext 下载内容，只返回响应体
+func (f *Fetcher) FetchContext(ctx context.Context, url string) ([]byte, error) {
+	resp, err := f.FetchWithMeta(ctx, url)
 	if err != nil {
 		return nil, err
 	}
+	return resp.Body, nil
+}
+
+// FetchWithMeta 下载内容并返回响应头，用于读取 subscription-userinfo 等信息
+//
+// file://、data: 与本地路径直接读取，不经过缓存。
+// 启用缓存时 TTL 内直接返回缓存，过期后携带 If-None-Match/If-Modified-Since 验证，
+// 上游出错时返回过期的缓存。
+func (f *Fetcher) FetchWithMeta(ctx context.Context, url string) (*Response, error) {
+	if !IsRemote(url) {
+		return f.localResponse(url)
+	}
+	if f.cache == nil {
+		body, resp, err := f.download(ctx, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Body: body, Header: resp.Header}, nil
+	}
 
-	req.Header.Set("User-Agent", "clash-verge/v2.4.5")
+	cached, found := f.cache.Get(url)
+	if found && f.cache.fresh(cached) {
+		f.cache.record(url, cacheHit)
+		return &Response{Body: cached.Body, Header: cached.Header}, nil
+	}
 
-	resp, err := f.client.Do(req)
+	body, resp, err := f.download(ctx, url, cached)
 	if err != nil {
+		if found {
+			log.Printf("下载 %s 失败: %v", url, err)
+			f.cache.record(url, cacheStale)
+			return &Response{Body: cached.Body, Header: cached.Header}, nil
+		}
 		return nil, err
 	}
+
+	entry := &CacheEntry{
+		URL:          url,
+		Body:         body,
+		Header:       resp.Header,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		f.cache.record(url, cacheRevalidated)
+		// 304 只携带部分响应头，以缓存为准
+		entry.Body = cached.Body
+		entry.Header = cached.Header
+		entry.ETag = defaultIfEmpty(entry.ETag, cached.ETag)
+		entry.LastModified = defaultIfEmpty(entry.LastModified, cached.LastModified)
+	} else {
+		f.cache.record(url, cacheMiss)
+	}
+	f.cache.Put(entry)
+	return &Response{Body: entry.Body, Header: entry.Header}, nil
+}
+
+// download 下载内容，临时错误按指数退避加随机抖动重试
+func (f *Fetcher) download(ctx context.Context, url string, cached *CacheEntry) ([]byte, *http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		body, resp, err := f.request(ctx, url, cached)
+		if err == nil || attempt >= f.retries || !f.retryable(ctx, err) {
+			return body, resp, err
+		}
+
+		// 第 n 次重试等待 delay*2^n 的 50%~100%
+		delay := f.retryDelay << attempt
+		delay = delay/2 + rand.N(delay/2+1)
+		log.Printf("下载 %s 失败，%v 后重试: %v", url, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// request 发送单次请求，cached 不为空时发送条件请求
+func (f *Fetcher) request(ctx context.Context, url string, cached *CacheEntry) ([]byte, *http.Response, error) {
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key, values := range f.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer resp.Body.Close()
 
-	return io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return nil, resp, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	body, err := f.readBody(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	return body, resp, nil
+}
+
+// readBody 按 Content-Encoding 解压响应体并限制大小
+func (f *Fetcher) readBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	case "", "identity":
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", resp.Header.Get("Content-Encoding"))
+	}
+
+	if f.maxBodySize <= 0 {
+		return io.ReadAll(reader)
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, f.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.maxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+// retryable 判断错误是否值得重试：429、5xx 与网络错误可以重试，调用方取消时不再重试
+func (f *Fetcher) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	return networkError(err)
+}
+
+// networkError 判断是否为连接、超时等网络错误，地址无效、解压失败、响应体过大等错误重试也无济于事
+func networkError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Op == "parse" {
+			return false
+		}
+		// 连接被对端关闭
+		if errors.Is(urlErr.Err, io.EOF) {
+			return true
+		}
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func defaultIfEmpty(str, def string) string {
+	if str == "" {
+		return def
+	}
+	return str
 }
@@ -0,0 +1,145 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestFetch(t *testing.T) {
+	attempts := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		_, _ = gw.Write([]byte("gzip body"))
+		_ = gw.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/br", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		bw := brotli.NewWriter(&buf)
+		_, _ = bw.Write([]byte("br body"))
+		_ = bw.Close()
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	mux.HandleFunc("/headers", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.UserAgent() + "|" + r.Header.Get("X-Token")))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	encodingAttempts := 0
+	mux.HandleFunc("/zstd", func(w http.ResponseWriter, r *http.Request) {
+		encodingAttempts++
+		w.Header().Set("Content-Encoding", "zstd")
+		_, _ = w.Write([]byte("zstd body"))
+	})
+	upstream := httptest.NewServer(mux)
+	defer upstream.Close()
+
+	f := NewFetcher(
+		WithRetry(3, time.Millisecond),
+		WithMaxBodySize(1024),
+		WithTimeout(50*time.Millisecond),
+		WithUserAgent("goconverter-test"),
+		WithHeader("X-Token", "secret"),
+	)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr func(error) bool
+	}{
+		{path: "/flaky", want: "ok"},
+		{path: "/gzip", want: "gzip body"},
+		{path: "/br", want: "br body"},
+		{path: "/headers", want: "goconverter-test|secret"},
+		{path: "/missing", wantErr: func(err error) bool {
+			var statusErr *StatusError
+			return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound && !statusErr.Temporary()
+		}},
+		{path: "/large", wantErr: func(err error) bool { return errors.Is(err, ErrBodyTooLarge) }},
+		{path: "/zstd", wantErr: func(err error) bool { return strings.Contains(err.Error(), "unsupported content encoding") }},
+		{path: "/slow", wantErr: func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body, err := f.Fetch(upstream.URL + tt.path)
+			if tt.wantErr != nil {
+				if err == nil || !tt.wantErr(err) {
+					t.Fatalf("Fetch() error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("Fetch() = %q, want %q", body, tt.want)
+			}
+		})
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	// 解压失败不是临时错误，只请求一次
+	if encodingAttempts != 1 {
+		t.Errorf("unsupported encoding attempts = %d, want 1", encodingAttempts)
+	}
+	if _, err := f.Fetch("http://[::1"); err == nil {
+		t.Error("Fetch() should fail for malformed url")
+	}
+}
+
+func TestFetchProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 经过 HTTP 代理的请求使用完整 URL
+		_, _ = w.Write([]byte("via proxy " + r.URL.Host))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	body, err := NewFetcher(WithProxy(proxyURL)).Fetch("http://sub.example.com/api")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(body) != "via proxy sub.example.com" {
+		t.Errorf("Fetch() = %q", body)
+	}
+}
+
+func TestFetchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewFetcher().FetchContext(ctx, "http://127.0.0.1:1/"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,138 @@
+// internal/fetcher/local.go
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"goconverter/internal/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsRemote 判断地址是否需要通过 HTTP 下载，file://、data: 与本地路径返回 false
+func IsRemote(rawURL string) bool {
+	lower := strings.ToLower(rawURL)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// ResolveReference 以 base 为基准解析相对地址 ref
+//
+// ref 带有 scheme 或 base 为空时原样返回。
+// base 可以是 http(s) 地址、file:// 地址或本地路径，/ 开头的 ref 在远程地址下解析为同一主机的路径。
+func ResolveReference(base, ref string) string {
+	if base == "" || ref == "" || hasScheme(ref) {
+		return ref
+	}
+	if IsRemote(base) || strings.HasPrefix(strings.ToLower(base), "file:") {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return ref
+		}
+		refURL, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return ref
+		}
+		return baseURL.ResolveReference(refURL).String()
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(filepath.Dir(base), ref)
+}
+
+// readLocal 读取 file://、data: 与本地路径，相对路径以 baseDir 为基准
+func (f *Fetcher) readLocal(rawURL string) ([]byte, error) {
+	lower := strings.ToLower(rawURL)
+	if strings.HasPrefix(lower, "data:") {
+		return decodeDataURI(rawURL)
+	}
+
+	if !f.localFiles {
+		return nil, fmt.Errorf("local file access is disabled: %s", rawURL)
+	}
+
+	path := rawURL
+	if strings.HasPrefix(lower, "file:") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file url %s: %v", rawURL, err)
+		}
+		// file:relative/path 与 file://host/path 都按本地路径处理
+		path = u.Opaque
+		if path == "" {
+			path = u.Path
+			if u.Host != "" && u.Host != "localhost" {
+				path = u.Host + u.Path
+			}
+		}
+		path = filepath.FromSlash(path)
+	}
+	if !filepath.IsAbs(path) && f.baseDir != "" {
+		path = filepath.Join(f.baseDir, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if f.maxBodySize <= 0 {
+		return io.ReadAll(file)
+	}
+	body, err := io.ReadAll(io.LimitReader(file, f.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.maxBodySize {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, ErrBodyTooLarge)
+	}
+	return body, nil
+}
+
+// localResponse 本地内容没有响应头
+func (f *Fetcher) localResponse(rawURL string) (*Response, error) {
+	body, err := f.readLocal(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Body: body, Header: make(http.Header)}, nil
+}
+
+// decodeDataURI 解析 data:[<mediatype>][;base64],<data>
+func decodeDataURI(rawURL string) ([]byte, error) {
+	meta, data, found := strings.Cut(rawURL[len("data:"):], ",")
+	if !found {
+		return nil, errors.New("invalid data uri: missing comma")
+	}
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		decoded, err := utils.Base64Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data uri: %v", err)
+		}
+		return []byte(decoded), nil
+	}
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data uri: %v", err)
+	}
+	return []byte(decoded), nil
+}
+
+// hasScheme 判断地址是否带有 scheme，Windows 盘符不算
+func hasScheme(rawURL string) bool {
+	index := strings.Index(rawURL, ":")
+	if index <= 1 {
+		return false
+	}
+	for _, c := range rawURL[:index] {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
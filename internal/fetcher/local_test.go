@@ -0,0 +1,64 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveReference(t *testing.T) {
+	tests := []struct {
+		base, ref, want string
+	}{
+		{"", "rules/a.list", "rules/a.list"},
+		{"https://example.com/config/my.ini", "rules/a.list", "https://example.com/config/rules/a.list"},
+		{"https://example.com/config/my.ini", "/rules/a.list", "https://example.com/rules/a.list"},
+		{"https://example.com/config/my.ini", "https://cdn.example.com/a.list", "https://cdn.example.com/a.list"},
+		{"https://example.com/config/my.ini", "data:,DOMAIN,a.com", "data:,DOMAIN,a.com"},
+		{"file:///srv/config/my.ini", "../rules/a.list", "file:///srv/rules/a.list"},
+		{filepath.Join("config", "my.ini"), "rules/a.list", filepath.Join("config", "rules", "a.list")},
+	}
+	for _, tt := range tests {
+		if got := ResolveReference(tt.base, tt.ref); got != tt.want {
+			t.Errorf("ResolveReference(%q, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestFetchLocal(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sub.txt"), []byte("local content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs(filepath.Join(dir, "sub.txt"))
+
+	f := NewFetcher(WithBaseDir(dir))
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"sub.txt", "local content"},
+		{abs, "local content"},
+		{"file://" + filepath.ToSlash(abs), "local content"},
+		{"data:,DOMAIN-SUFFIX%2Cexample.com", "DOMAIN-SUFFIX,example.com"},
+		{"data:text/plain;base64,aGVsbG8=", "hello"},
+	}
+	for _, tt := range tests {
+		body, err := f.Fetch(tt.url)
+		if err != nil {
+			t.Errorf("Fetch(%q) error = %v", tt.url, err)
+			continue
+		}
+		if string(body) != tt.want {
+			t.Errorf("Fetch(%q) = %q, want %q", tt.url, body, tt.want)
+		}
+	}
+
+	restricted := NewFetcher(WithBaseDir(dir), WithLocalFiles(false))
+	if _, err := restricted.Fetch("sub.txt"); err == nil {
+		t.Error("Fetch() should reject local files when disabled")
+	}
+	if body, err := restricted.Fetch("data:,ok"); err != nil || string(body) != "ok" {
+		t.Errorf("Fetch(data:) = %q, %v", body, err)
+	}
+}
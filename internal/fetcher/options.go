@@ -0,0 +1,83 @@
+// internal/fetcher/options.go
+package fetcher
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultTimeout     = 20 * time.Second
+	defaultRetries     = 2
+	defaultRetryDelay  = 500 * time.Millisecond
+	defaultMaxBodySize = 32 << 20
+	defaultUserAgent   = "clash-verge/v2.4.5"
+)
+
+// Option Fetcher 的可选配置
+type Option func(*Fetcher)
+
+// WithTimeout 设置单次请求的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *Fetcher) {
+		f.timeout = timeout
+	}
+}
+
+// WithRetry 设置临时错误的重试次数与首次重试的等待时间，之后按指数退避
+func WithRetry(retries int, delay time.Duration) Option {
+	return func(f *Fetcher) {
+		f.retries = retries
+		f.retryDelay = delay
+	}
+}
+
+// WithMaxBodySize 设置解压后响应体的最大字节数
+func WithMaxBodySize(size int64) Option {
+	return func(f *Fetcher) {
+		f.maxBodySize = size
+	}
+}
+
+// WithUserAgent 设置 User-Agent，部分机场按 User-Agent 返回不同格式的订阅
+func WithUserAgent(userAgent string) Option {
+	return func(f *Fetcher) {
+		f.userAgent = userAgent
+	}
+}
+
+// WithHeader 添加请求头
+func WithHeader(key, value string) Option {
+	return func(f *Fetcher) {
+		f.headers.Add(key, value)
+	}
+}
+
+// WithProxy 通过 HTTP 或 SOCKS5 代理下载，如 http://127.0.0.1:7890、socks5://127.0.0.1:7891
+func WithProxy(proxyURL *url.URL) Option {
+	return func(f *Fetcher) {
+		f.transport.Proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithBaseDir 设置本地相对路径的基准目录，默认为当前目录
+func WithBaseDir(dir string) Option {
+	return func(f *Fetcher) {
+		f.baseDir = dir
+	}
+}
+
+// WithLocalFiles 是否允许读取 file:// 与本地路径，服务端处理请求参数时应关闭，data: 不受影响
+func WithLocalFiles(enabled bool) Option {
+	return func(f *Fetcher) {
+		f.localFiles = enabled
+	}
+}
+
+// WithCache 启用响应缓存
+func WithCache(cache *Cache) Option {
+	return func(f *Fetcher) {
+		f.cache = cache
+	}
+}
@@ -0,0 +1,237 @@
+// internal/server/handler.go
+package server
+
+import (
+	"context"
+	"fmt"
+	"goconverter/internal/config"
+	"goconverter/internal/converter"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/subscription/parser"
+	"goconverter/internal/subscription/processor"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// handleConvert 兼容 subconverter 的 /sub 接口
+//
+// 参数: target, url(多个用 | 分隔), config, include, exclude, rename, emoji, udp, list, expand, ver, interval
+// 扩展参数: info_node(插入流量信息节点), add_emoji, remove_emoji, protocols, exclude_protocols(逗号分隔), ports(端口范围，! 开头表示排除), block_servers(逗号分隔的 CIDR 或域名后缀)
+func (s *Server) handleConvert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		target := query.Get("target")
+		if target == "" {
+			http.Error(w, "missing target", http.StatusBadRequest)
+			return
+		}
+		conv, err := converter.NewConverter(target, &converter.BaseInfo{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if clash, ok := conv.(interface{ SetRuleProviders(bool) }); ok && query.Has("expand") {
+			clash.SetRuleProviders(!queryBool(query, "expand"))
+		}
+		if surge, ok := conv.(*converter.SurgeConverter); ok {
+			if query.Has("ver") {
+				version, _ := strconv.Atoi(query.Get("ver"))
+				if err := surge.SetVersion(version); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			interval, _ := strconv.Atoi(query.Get("interval"))
+			surge.SetManagedConfig(requestURL(r), interval)
+		}
+
+		subscriptionURLs := query.Get("url")
+		if subscriptionURLs == "" {
+			http.Error(w, "missing url", http.StatusBadRequest)
+			return
+		}
+
+		nodes, info, err := s.fetchNodes(r.Context(), strings.Split(subscriptionURLs, "|"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		// list 模式只在显式指定 config 时加载外部配置
+		list := queryBool(query, "list")
+		var cfg *config.ClashConfig
+		if !list || query.Has("config") {
+			cfg, err = s.loadConfig(r.Context(), query.Get("config"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+
+		nodes, err = processNodes(nodes, query, cfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(nodes) == 0 {
+			http.Error(w, "no nodes were found", http.StatusBadRequest)
+			return
+		}
+		if queryBool(query, "info_node") {
+			nodes = processor.PrependInfoNode(nodes, info)
+		}
+
+		var result string
+		if list {
+			result, err = conv.ConvertList(nodes)
+		} else {
+			result, err = conv.Convert(nodes, cfg)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("转换失败: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		if info != nil {
+			w.Header().Set(parser.UserInfoHeader, info.String())
+		}
+		w.Header().Set("Content-Type", conv.ContentType())
+		w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(conv.FileName()))
+		_, _ = w.Write([]byte(result))
+	}
+}
+
+// fetchNodes 下载并解析所有订阅，按顺序合并并去重，同时汇总流量信息
+func (s *Server) fetchNodes(ctx context.Context, urls []string) ([]*model.Node, *parser.UserInfo, error) {
+	sources := make([]processor.Source, 0, len(urls))
+	for _, value := range urls {
+		if value == "" {
+			continue
+		}
+		name, subscriptionURL := processor.ParseSource(value)
+		resp, err := s.requestFetcher.FetchWithMeta(ctx, subscriptionURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("加载订阅失败: %v", err)
+		}
+		content := string(resp.Body)
+		parsed, format, err := parser.ParseSubscriptionWithFormat(content, parser.FormatAuto)
+		if err != nil {
+			log.Printf("解析订阅 %s 失败: %v", subscriptionURL, err)
+		} else {
+			log.Printf("订阅 %s 格式: %s", subscriptionURL, format)
+		}
+		// SIP008 订阅的流量信息在内容中
+		info := parser.ParseUserInfo(resp.Header.Get(parser.UserInfoHeader))
+		if info == nil && format == parser.FormatSIP008 {
+			info = parser.UserInfoFromSIP008(content)
+		}
+		sources = append(sources, processor.Source{Name: name, Nodes: parsed, Info: info})
+	}
+	return processor.Merge(sources), processor.MergeUserInfo(sources), nil
+}
+
+// loadConfig 下载并解析外部配置，configURL 为空时使用默认配置
+//
+// 请求中指定的配置及其规则集不允许读取服务器上的本地文件，客户端断开时停止下载规则集
+func (s *Server) loadConfig(ctx context.Context, configURL string) (*config.ClashConfig, error) {
+	if configURL == "" {
+		return config.LoadConfigContext(ctx, s.fetcher, s.configURL, s.rulesetWorkers)
+	}
+	return config.LoadConfigContext(ctx, s.requestFetcher, configURL, s.rulesetWorkers)
+}
+
+// processNodes 按请求参数与外部配置过滤、重命名节点
+func processNodes(nodes []*model.Node, query url.Values, cfg *config.ClashConfig) ([]*model.Node, error) {
+	filter, err := processor.NewFilter(filterOptions(query, cfg))
+	if err != nil {
+		return nil, err
+	}
+	nodes = filter.Apply(nodes)
+
+	nameOptions, err := nameOptions(query, cfg)
+	if err != nil {
+		return nil, err
+	}
+	processor.ProcessNames(nodes, nameOptions)
+	if query.Has("udp") {
+		processor.ForceUDP(nodes, queryBool(query, "udp"))
+	}
+
+	return nodes, nil
+}
+
+// filterOptions 汇总过滤条件，请求中的 include/exclude 覆盖外部配置的 include_remarks/exclude_remarks
+func filterOptions(query url.Values, cfg *config.ClashConfig) processor.FilterOptions {
+	options := processor.FilterOptions{
+		IncludeRemarks:   query["include"],
+		ExcludeRemarks:   query["exclude"],
+		Protocols:        query["protocols"],
+		ExcludeProtocols: query["exclude_protocols"],
+		Ports:            query.Get("ports"),
+		BlockServers:     strings.Split(query.Get("block_servers"), ","),
+	}
+	if cfg != nil && !query.Has("include") {
+		options.IncludeRemarks = cfg.IncludeRemarks
+	}
+	if cfg != nil && !query.Has("exclude") {
+		options.ExcludeRemarks = cfg.ExcludeRemarks
+	}
+	return options
+}
+
+// nameOptions 汇总重命名与旗帜选项，请求参数覆盖外部配置
+//
+// emoji 同时控制 add_emoji 与 remove_emoji，两者也可单独指定
+func nameOptions(query url.Values, cfg *config.ClashConfig) (processor.NameOptions, error) {
+	var options processor.NameOptions
+	renameRules := query["rename"]
+	var emojiRules []string
+	if cfg != nil {
+		if !query.Has("rename") {
+			renameRules = cfg.RenameRules
+		}
+		emojiRules = cfg.EmojiRules
+		options.AddEmoji = cfg.AddEmoji
+		options.RemoveOldEmoji = cfg.RemoveOldEmoji
+	}
+	if query.Has("emoji") {
+		options.AddEmoji = queryBool(query, "emoji")
+		options.RemoveOldEmoji = options.AddEmoji
+	}
+	if query.Has("add_emoji") {
+		options.AddEmoji = queryBool(query, "add_emoji")
+	}
+	if query.Has("remove_emoji") {
+		options.RemoveOldEmoji = queryBool(query, "remove_emoji")
+	}
+
+	var err error
+	if options.RenameRules, err = processor.ParseRenameRules(renameRules...); err != nil {
+		return options, err
+	}
+	if options.EmojiRules, err = processor.ParseEmojiRules(emojiRules); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// requestURL 还原客户端请求的完整地址，用于托管配置
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+func queryBool(query url.Values, key string) bool {
+	value, _ := strconv.ParseBool(query.Get(key))
+	return value
+}
@@ -2,25 +2,63 @@
 package server
 
 import (
+	"goconverter/internal/config"
+	"goconverter/internal/fetcher"
 	"net/http"
+	"time"
+)
+
+const (
+	// defaultCacheTTL 订阅与配置的缓存时间
+	defaultCacheTTL = 5 * time.Minute
+	// rulesetCacheTTL GitHub 上的规则集更新不频繁，缓存更久以免被限流
+	rulesetCacheTTL = 6 * time.Hour
 )
 
 type Server struct {
-	router *http.ServeMux
+	router         *http.ServeMux
+	fetcher        *fetcher.Fetcher // 读取默认配置，允许本地文件
+	requestFetcher *fetcher.Fetcher // 读取请求参数中的地址，禁止本地文件
+	configURL      string           // 未指定 config 参数时使用的默认配置
+	rulesetWorkers int              // 每个请求同时下载的规则集数量
+}
+
+// NewCache 创建服务使用的缓存，GitHub 与 jsDelivr 上的规则集使用更长的 TTL
+func NewCache(dir string, ttl time.Duration) *fetcher.Cache {
+	cache := fetcher.NewCache(dir, ttl)
+	cache.SetTTL("https://raw.githubusercontent.com/", rulesetCacheTTL)
+	cache.SetTTL("https://cdn.jsdelivr.net/", rulesetCacheTTL)
+	return cache
 }
 
-func NewServer() *Server {
+// NewServer 创建服务，opts 用于配置下载订阅与规则集的 Fetcher，默认使用内存缓存
+func NewServer(configURL string, opts ...fetcher.Option) *Server {
+	opts = append([]fetcher.Option{fetcher.WithCache(NewCache("", defaultCacheTTL))}, opts...)
 	s := &Server{
-		router: http.NewServeMux(),
+		router:         http.NewServeMux(),
+		fetcher:        fetcher.NewFetcher(opts...),
+		requestFetcher: fetcher.NewFetcher(append(opts, fetcher.WithLocalFiles(false))...),
+		configURL:      configURL,
+		rulesetWorkers: config.DefaultRulesetWorkers,
 	}
 	s.routes()
 	return s
 }
 
+// SetRulesetWorkers 设置每个请求同时下载的规则集数量
+func (s *Server) SetRulesetWorkers(workers int) {
+	s.rulesetWorkers = workers
+}
+
 func (s *Server) routes() {
+	s.router.HandleFunc("/sub", s.handleConvert())
 	s.router.HandleFunc("/convert", s.handleConvert())
 }
 
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
 func (s *Server) Run(addr string) error {
 	return http.ListenAndServe(addr, s.router)
 }
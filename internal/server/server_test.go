@@ -0,0 +1,146 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testSubscription = `proxies:
+  - {name: "香港 01", type: trojan, server: hk.example.com, port: 443, password: pass}
+  - {name: "日本 01", type: trojan, server: jp.example.com, port: 443, password: pass}
+  - {name: "剩余流量 10GB", type: trojan, server: info.example.com, port: 443, password: pass}
+`
+
+const testConfig = `[custom]
+ruleset=🎯 全球直连,[]GEOIP,CN
+ruleset=🐟 漏网之鱼,[]FINAL
+custom_proxy_group=🚀 节点选择` + "`select`[]DIRECT`.*" + `
+custom_proxy_group=🎯 全球直连` + "`select`[]DIRECT" + `
+custom_proxy_group=🐟 漏网之鱼` + "`select`[]🚀 节点选择" + `
+exclude_remarks=(剩余流量|到期时间)
+`
+
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sub.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Subscription-Userinfo", "upload=1073741824; download=9663676416; total=139586437120; expire=0")
+		_, _ = w.Write([]byte(testSubscription))
+	})
+	mux.HandleFunc("/config.ini", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testConfig))
+	})
+	upstream := httptest.NewServer(mux)
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func TestHandleConvert(t *testing.T) {
+	upstream := newUpstream(t)
+	s := NewServer(upstream.URL + "/config.ini")
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+		contains   []string
+		excludes   []string
+		userinfo   string
+	}{
+		{
+			name:       "missing target",
+			query:      url.Values{"url": {upstream.URL + "/sub.yaml"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported target",
+			query:      url.Values{"target": {"unknown"}, "url": {upstream.URL + "/sub.yaml"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "clash with filters",
+			query: url.Values{
+				"target":  {"clash"},
+				"url":     {upstream.URL + "/sub.yaml"},
+				"exclude": {"剩余流量"},
+				"rename":  {"01@02"},
+				"emoji":   {"true"},
+			},
+			wantStatus: http.StatusOK,
+			contains:   []string{"🇭🇰 香港 02", "🇯🇵 日本 02", "MATCH,🐟 漏网之鱼", "proxy-groups"},
+			excludes:   []string{"剩余流量"},
+		},
+		{
+			name: "config exclude_remarks and server filter",
+			query: url.Values{
+				"target":        {"clash"},
+				"url":           {upstream.URL + "/sub.yaml"},
+				"block_servers": {"jp.example.com"},
+			},
+			wantStatus: http.StatusOK,
+			contains:   []string{"香港 01"},
+			excludes:   []string{"剩余流量", "日本 01"},
+		},
+		{
+			name: "info node",
+			query: url.Values{
+				"target":    {"clash"},
+				"url":       {upstream.URL + "/sub.yaml"},
+				"info_node": {"true"},
+			},
+			wantStatus: http.StatusOK,
+			contains:   []string{"剩余 120GB", "server: 127.0.0.1"},
+			userinfo:   "upload=1073741824; download=9663676416; total=139586437120",
+		},
+		{
+			name: "multiple urls as list",
+			query: url.Values{
+				"target":  {"clash"},
+				"url":     {upstream.URL + "/sub.yaml|" + upstream.URL + "/sub.yaml"},
+				"include": {"香港"},
+				"list":    {"true"},
+			},
+			wantStatus: http.StatusOK,
+			contains:   []string{"proxies:", "hk.example.com"},
+			userinfo:   "upload=2147483648; download=19327352832; total=279172874240",
+			excludes:   []string{"proxy-groups", "jp.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/sub?"+tt.query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			body := rec.Body.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body should not contain %q:\n%s", unwanted, body)
+				}
+			}
+			if tt.wantStatus == http.StatusOK {
+				if got := rec.Header().Get("Content-Type"); got != "text/yaml; charset=utf-8" {
+					t.Errorf("Content-Type = %q", got)
+				}
+				if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "clash.yaml") {
+					t.Errorf("Content-Disposition = %q", got)
+				}
+				if got := rec.Header().Get("Subscription-Userinfo"); tt.userinfo != "" && got != tt.userinfo {
+					t.Errorf("Subscription-Userinfo = %q, want %q", got, tt.userinfo)
+				}
+			}
+		})
+	}
+}
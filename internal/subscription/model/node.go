@@ -3,6 +3,7 @@ package model
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 )
 
@@ -14,8 +15,13 @@ const (
 	TypeSSR       NodeType = "ssr"
 	TypeVmess     NodeType = "vmess"
 	TypeTrojan    NodeType = "trojan"
+	TypeVless     NodeType = "vless"
 	TypeHysteria2 NodeType = "hysteria2"
 	TypeAnyTLS    NodeType = "anytls"
+	TypeTUIC      NodeType = "tuic"
+	TypeWireGuard NodeType = "wireguard"
+	TypeSocks5    NodeType = "socks5"
+	TypeHTTP      NodeType = "http"
 )
 
 // Node 定义统一的节点结构
@@ -24,16 +30,18 @@ type Node struct {
 	Name       string            `json:"name"`
 	Server     string            `json:"server"`
 	Port       int               `json:"port"`
+	Username   string            `json:"username"` // SOCKS5/HTTP 用户名
 	Password   string            `json:"password"`
-	Cipher     string            `json:"cipher"`      // 加密方法
-	UDP        bool              `json:"udp"`         // 是否支持UDP
-	Plugin     string            `json:"plugin"`      // SS插件
-	PluginOpts map[string]string `json:"plugin_opts"` // SS插件参数
+	Cipher     string            `json:"cipher"`       // 加密方法
+	UDP        bool              `json:"udp"`          // 是否支持UDP
+	Plugin     string            `json:"plugin"`       // SS插件
+	PluginOpts map[string]string `json:"plugin_opts"`  // SS插件参数
+	UDPOverTCP bool              `json:"udp_over_tcp"` // SS UDP over TCP
 
 	// SSR特定参数
 	Protocol      string `json:"protocol"`       // SSR协议
 	ProtocolParam string `json:"protocol_param"` // SSR协议参数
-	Obfs          string `json:"obfs"`           // SSR混淆
+	Obfs          string `json:"obfs"`           // SSR混淆，Hysteria2 为 salamander
 	ObfsParam     string `json:"obfs_param"`     // SSR混淆参数
 
 	// VMess特定参数
@@ -46,13 +54,58 @@ type Node struct {
 	WsPath    string            `json:"ws_path"`    // WebSocket路径
 	WsHeaders map[string]string `json:"ws_headers"` // WebSocket请求头
 
+	WsMaxEarlyData    int    `json:"ws_max_early_data"`    // WebSocket 0-RTT 最大数据长度
+	WsEarlyDataHeader string `json:"ws_early_data_header"` // WebSocket 0-RTT 请求头名称
+
+	// 传输层参数
+	GrpcServiceName string   `json:"grpc_service_name"` // gRPC serviceName
+	H2Host          []string `json:"h2_host"`           // HTTP/2 Host
+	H2Path          string   `json:"h2_path"`           // HTTP/2 路径
+
 	// Trojan特定参数
 	AllowInsecure bool `json:"allow_insecure"` // 是否允许不安全TLS
 
+	// VLESS特定参数
+	Flow              string `json:"flow"`               // 流控，如 xtls-rprx-vision
+	ClientFingerprint string `json:"client_fingerprint"` // uTLS 指纹
+	RealityPublicKey  string `json:"reality_public_key"` // REALITY 公钥
+	RealityShortID    string `json:"reality_short_id"`   // REALITY ShortID
+	RealitySpiderX    string `json:"reality_spider_x"`   // REALITY SpiderX
+	PacketEncoding    string `json:"packet_encoding"`    // UDP 包编码：packetaddr/xudp
+
+	// Hysteria2特定参数
+	ObfsPassword    string `json:"obfs_password"`    // 混淆密码
+	Ports           string `json:"ports"`            // 端口跳跃范围，如 20000-30000
+	Up              string `json:"up"`               // 上行带宽
+	Down            string `json:"down"`             // 下行带宽
+	CertFingerprint string `json:"cert_fingerprint"` // 证书 SHA256 指纹
+
+	// TUIC特定参数
+	CongestionControl string `json:"congestion_control"` // 拥塞控制：cubic/new_reno/bbr
+	UDPRelayMode      string `json:"udp_relay_mode"`     // UDP 转发模式：native/quic
+	DisableSNI        bool   `json:"disable_sni"`        // 是否禁用 SNI
+	ReduceRTT         bool   `json:"reduce_rtt"`         // 是否启用 0-RTT
+
+	// WireGuard特定参数
+	PrivateKey   string   `json:"private_key"`    // 本地私钥
+	PublicKey    string   `json:"public_key"`     // 对端公钥
+	PreSharedKey string   `json:"pre_shared_key"` // 预共享密钥
+	IP           string   `json:"ip"`             // 本地 IPv4 地址
+	IPv6         string   `json:"ipv6"`           // 本地 IPv6 地址
+	Reserved     []int    `json:"reserved"`       // reserved 字节
+	MTU          int      `json:"mtu"`            // MTU
+	AllowedIPs   []string `json:"allowed_ips"`    // 允许的 IP 段
+
 	// 通用参数
-	Group    string   `json:"group"` // 分组
-	Tags     []string `json:"tags"`  // 标签
-	Settings map[string]string
+	Group       string                 `json:"group"`        // 分组
+	GroupID     int                    `json:"group_id"`     // 所属订阅序号，从 0 开始
+	Tags        []string               `json:"tags"`         // 标签
+	DialerProxy string                 `json:"dialer_proxy"` // 前置代理
+	IPVersion   string                 `json:"ip_version"`   // 出站 IP 版本
+	TFO         bool                   `json:"tfo"`          // TCP Fast Open
+	Smux        map[string]interface{} `json:"smux"`         // sing-mux 多路复用配置
+	Extra       map[string]interface{} `json:"extra"`        // 未建模的 Clash 字段，原样输出
+	Settings    map[string]string
 }
 
 // ToClash 将节点转换为Clash配置
@@ -69,7 +122,13 @@ func (n *Node) ToClash() map[string]interface{} {
 		proxy["password"] = n.Password
 		if n.Plugin != "" {
 			proxy["plugin"] = n.Plugin
-			proxy["plugin-opts"] = n.PluginOpts
+			proxy["plugin-opts"] = clashPluginOpts(n.PluginOpts)
+		}
+		if n.UDPOverTCP {
+			proxy["udp-over-tcp"] = true
+		}
+		if n.ClientFingerprint != "" {
+			proxy["client-fingerprint"] = n.ClientFingerprint
 		}
 
 	case TypeSSR:
@@ -88,53 +147,230 @@ func (n *Node) ToClash() map[string]interface{} {
 		proxy["cipher"] = defaultIfEmpty(n.Cipher, "auto")
 		if n.TLS {
 			proxy["tls"] = true
-			proxy["servername"] = defaultIfEmpty(n.SNI, n.Server)
-			if len(n.ALPN) > 0 {
-				proxy["alpn"] = n.ALPN
-			}
 		}
-		if n.Network == "ws" {
-			proxy["network"] = n.Network
-			wsOpts := make(map[string]interface{})
-			wsOpts["path"] = defaultIfEmpty(n.WsPath, "/")
-			if len(n.WsHeaders) > 0 {
-				wsOpts["headers"] = n.WsHeaders
-			}
-			proxy["ws-opts"] = wsOpts
+		n.setClashTLS(proxy, "servername")
+		n.setClashTransport(proxy)
+		if n.PacketEncoding != "" {
+			proxy["packet-encoding"] = n.PacketEncoding
+		}
+
+	case TypeVless:
+		proxy["type"] = "vless"
+		proxy["uuid"] = n.UUID
+		if n.Flow != "" {
+			proxy["flow"] = n.Flow
+		}
+		if n.TLS {
+			proxy["tls"] = true
+		}
+		n.setClashTLS(proxy, "servername")
+		n.setClashTransport(proxy)
+		if n.PacketEncoding != "" {
+			proxy["packet-encoding"] = n.PacketEncoding
 		}
 
 	case TypeTrojan:
 		proxy["type"] = "trojan"
 		proxy["password"] = n.Password
+		n.setClashTLS(proxy, "sni")
+		n.setClashTransport(proxy)
 
-		// proxy["sni"] = defaultIfEmpty(n.SNI, n.Server)
-		proxy["skip-cert-verify"] = n.AllowInsecure
-		if len(n.ALPN) > 0 {
-			proxy["alpn"] = n.ALPN
-		}
-		if n.UDP {
-			proxy["udp"] = true
-		}
 	case TypeHysteria2:
 		proxy["type"] = "hysteria2"
 		proxy["password"] = n.Password
-
-		proxy["sni"] = defaultIfEmpty(n.SNI, n.Server)
-		proxy["skip-cert-verify"] = n.AllowInsecure
+		if n.Obfs != "" {
+			proxy["obfs"] = n.Obfs
+			proxy["obfs-password"] = n.ObfsPassword
+		}
+		if n.Ports != "" {
+			proxy["ports"] = n.Ports
+		}
+		if n.Up != "" {
+			proxy["up"] = clashBandwidth(n.Up)
+		}
+		if n.Down != "" {
+			proxy["down"] = clashBandwidth(n.Down)
+		}
+		n.setClashTLS(proxy, "sni")
 
 	case TypeAnyTLS:
 		proxy["type"] = "anytls"
 		proxy["password"] = n.Password
+		n.setClashTLS(proxy, "sni")
+		proxy["client-fingerprint"] = defaultIfEmpty(n.ClientFingerprint, "random")
 
-		// proxy["sni"] = defaultIfEmpty(n.SNI, n.Server)
-		proxy["skip-cert-verify"] = n.AllowInsecure
-		proxy["client-fingerprint"] = "random"
+	case TypeTUIC:
+		proxy["type"] = "tuic"
+		proxy["uuid"] = n.UUID
+		proxy["password"] = n.Password
+		n.setClashTLS(proxy, "sni")
+		if n.CongestionControl != "" {
+			proxy["congestion-controller"] = n.CongestionControl
+		}
+		if n.UDPRelayMode != "" {
+			proxy["udp-relay-mode"] = n.UDPRelayMode
+		}
+		if n.DisableSNI {
+			proxy["disable-sni"] = true
+		}
+		if n.ReduceRTT {
+			proxy["reduce-rtt"] = true
+		}
 
+	case TypeWireGuard:
+		proxy["type"] = "wireguard"
+		proxy["private-key"] = n.PrivateKey
+		proxy["public-key"] = n.PublicKey
+		if n.PreSharedKey != "" {
+			proxy["pre-shared-key"] = n.PreSharedKey
+		}
+		if n.IP != "" {
+			proxy["ip"] = n.IP
+		}
+		if n.IPv6 != "" {
+			proxy["ipv6"] = n.IPv6
+		}
+		if len(n.Reserved) > 0 {
+			proxy["reserved"] = n.Reserved
+		}
+		if n.MTU > 0 {
+			proxy["mtu"] = n.MTU
+		}
+		if len(n.AllowedIPs) > 0 {
+			proxy["allowed-ips"] = n.AllowedIPs
+		}
+		proxy["udp"] = true
+
+	case TypeSocks5, TypeHTTP:
+		proxy["type"] = string(n.Type)
+		if n.Username != "" {
+			proxy["username"] = n.Username
+			proxy["password"] = n.Password
+		}
+		if n.TLS {
+			proxy["tls"] = true
+			n.setClashTLS(proxy, "sni")
+		}
+	}
+
+	if n.UDP && n.Type != TypeHTTP {
+		proxy["udp"] = true
+	}
+	if n.DialerProxy != "" {
+		proxy["dialer-proxy"] = n.DialerProxy
+	}
+	if n.IPVersion != "" {
+		proxy["ip-version"] = n.IPVersion
+	}
+	if n.TFO {
+		proxy["tfo"] = true
+	}
+	if len(n.Smux) > 0 {
+		proxy["smux"] = n.Smux
+	}
+	for key, value := range n.Extra {
+		if _, ok := proxy[key]; !ok {
+			proxy[key] = value
+		}
 	}
 
 	return proxy
 }
 
+// setClashTLS 设置 TLS 相关字段，sniKey 为 vmess/vless 的 servername 或其他协议的 sni
+func (n *Node) setClashTLS(proxy map[string]interface{}, sniKey string) {
+	if n.SNI != "" {
+		proxy[sniKey] = n.SNI
+	}
+	if n.AllowInsecure {
+		proxy["skip-cert-verify"] = true
+	}
+	if len(n.ALPN) > 0 {
+		proxy["alpn"] = n.ALPN
+	}
+	if n.ClientFingerprint != "" {
+		proxy["client-fingerprint"] = n.ClientFingerprint
+	}
+	if n.CertFingerprint != "" {
+		proxy["fingerprint"] = n.CertFingerprint
+	}
+	if n.RealityPublicKey != "" {
+		realityOpts := map[string]interface{}{
+			"public-key": n.RealityPublicKey,
+		}
+		if n.RealityShortID != "" {
+			realityOpts["short-id"] = n.RealityShortID
+		}
+		proxy["reality-opts"] = realityOpts
+	}
+}
+
+// setClashTransport 设置 vmess/vless/trojan 的传输层配置
+func (n *Node) setClashTransport(proxy map[string]interface{}) {
+	switch n.Network {
+	case "", "tcp":
+	case "ws", "httpupgrade":
+		proxy["network"] = "ws"
+		wsOpts := make(map[string]interface{})
+		wsOpts["path"] = defaultIfEmpty(n.WsPath, "/")
+		if len(n.WsHeaders) > 0 {
+			wsOpts["headers"] = n.WsHeaders
+		}
+		if n.WsMaxEarlyData > 0 {
+			wsOpts["max-early-data"] = n.WsMaxEarlyData
+		}
+		if n.WsEarlyDataHeader != "" {
+			wsOpts["early-data-header-name"] = n.WsEarlyDataHeader
+		}
+		if n.Network == "httpupgrade" {
+			wsOpts["v2ray-http-upgrade"] = true
+		}
+		proxy["ws-opts"] = wsOpts
+
+	case "grpc":
+		proxy["network"] = "grpc"
+		proxy["grpc-opts"] = map[string]interface{}{
+			"grpc-service-name": n.GrpcServiceName,
+		}
+
+	case "h2":
+		proxy["network"] = "h2"
+		h2Opts := map[string]interface{}{
+			"path": defaultIfEmpty(n.H2Path, "/"),
+		}
+		if len(n.H2Host) > 0 {
+			h2Opts["host"] = n.H2Host
+		}
+		proxy["h2-opts"] = h2Opts
+
+	default:
+		proxy["network"] = n.Network
+	}
+}
+
+// clashPluginOpts 还原插件参数中的布尔值和版本号
+func clashPluginOpts(opts map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(opts))
+	for key, value := range opts {
+		if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+			result[key] = b
+		} else if i, err := strconv.Atoi(value); err == nil && key == "version" {
+			result[key] = i
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// clashBandwidth 纯数字的带宽按 Mbps 输出为整数
+func clashBandwidth(value string) interface{} {
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+	return value
+}
+
 // ToJSON 将节点转换为JSON字符串
 func (n *Node) ToJSON() (string, error) {
 	data, err := json.Marshal(n)
@@ -168,8 +404,12 @@ func ValidateNode(node *Node) error {
 	if node.Port <= 0 || node.Port > 65535 {
 		return NewValidationError("invalid port number")
 	}
-	if node.Password == "" && node.Type != TypeVmess {
-		return NewValidationError("password is required")
+	switch node.Type {
+	case TypeVmess, TypeVless, TypeWireGuard, TypeSocks5, TypeHTTP:
+	default:
+		if node.Password == "" {
+			return NewValidationError("password is required")
+		}
 	}
 
 	switch node.Type {
@@ -191,6 +431,18 @@ func ValidateNode(node *Node) error {
 		if node.AlterID < 0 {
 			return NewValidationError("invalid alter_id for vmess")
 		}
+	case TypeVless:
+		if node.UUID == "" {
+			return NewValidationError("uuid is required for vless")
+		}
+	case TypeTUIC:
+		if node.UUID == "" {
+			return NewValidationError("uuid is required for tuic")
+		}
+	case TypeWireGuard:
+		if node.PrivateKey == "" || node.PublicKey == "" {
+			return NewValidationError("private key and public key are required for wireguard")
+		}
 	}
 
 	return nil
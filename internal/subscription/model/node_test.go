@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestValidateNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    Node
+		wantErr bool
+	}{
+		{
+			name: "vmess",
+			node: Node{Type: TypeVmess, Server: "example.com", Port: 443, UUID: "b831381d-6324-4d53-ad4f-8cda48b30811"},
+		},
+		{
+			name:    "vmess negative alter_id",
+			node:    Node{Type: TypeVmess, Server: "example.com", Port: 443, UUID: "b831381d-6324-4d53-ad4f-8cda48b30811", AlterID: -1},
+			wantErr: true,
+		},
+		{
+			name: "wireguard",
+			node: Node{Type: TypeWireGuard, Server: "example.com", Port: 51820, PrivateKey: "private", PublicKey: "public"},
+		},
+		{
+			name:    "wireguard missing key",
+			node:    Node{Type: TypeWireGuard, Server: "example.com", Port: 51820, PrivateKey: "private"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateNode(&tt.node); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
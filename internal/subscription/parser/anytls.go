@@ -0,0 +1,63 @@
+// internal/subscription/parser/anytls.go
+package parser
+
+import (
+	"goconverter/internal/subscription/model"
+	"net/url"
+	"strings"
+)
+
+type AnyTLSParser struct{}
+
+func NewAnyTLSParser() *AnyTLSParser {
+	return &AnyTLSParser{}
+}
+
+func (p *AnyTLSParser) Match(link string) bool {
+	return strings.HasPrefix(link, "anytls://")
+}
+
+// Parse 解析 anytls://password@host:port/?sni=...&insecure=1#name
+func (p *AnyTLSParser) Parse(link string) (*model.Node, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	node := &model.Node{
+		Type:              model.TypeAnyTLS,
+		Name:              u.Fragment,
+		Server:            u.Hostname(),
+		Port:              parseInt(u.Port()),
+		Password:          userinfoPassword(u),
+		TLS:               true,
+		SNI:               query.Get("sni"),
+		AllowInsecure:     parseBool(query.Get("insecure")),
+		ClientFingerprint: query.Get("fp"),
+		UDP:               true,
+		Settings:          make(map[string]string),
+	}
+	if node.Port == 0 {
+		node.Port = 443
+	}
+	if alpn := query.Get("alpn"); alpn != "" {
+		node.ALPN = strings.Split(alpn, ",")
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+
+	return node, nil
+}
+
+// Encode 生成 anytls:// 分享链接
+func (p *AnyTLSParser) Encode(node *model.Node) (string, error) {
+	query := url.Values{}
+	setParam(query, "sni", node.SNI)
+	setBoolParam(query, "insecure", node.AllowInsecure)
+	setParam(query, "fp", node.ClientFingerprint)
+	setParam(query, "alpn", strings.Join(node.ALPN, ","))
+
+	return buildLink("anytls", url.User(node.Password), node, query), nil
+}
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"goconverter/internal/subscription/model"
-	"log"
+	"goconverter/internal/utils"
 	"strings"
 
 	"github.com/goccy/go-yaml"
@@ -17,66 +17,170 @@ type Parser interface {
 	Match(link string) bool
 }
 
-// ParseSubscription 解析整个订阅内容
+// 订阅格式
+const (
+	FormatAuto    = "auto"    // 自动识别
+	FormatClashX  = "clashx"  // Clash YAML
+	FormatLine    = "line"    // 每行一个分享链接
+	FormatBase64  = "base64"  // base64 编码的分享链接列表
+	FormatSIP008  = "sip008"  // SIP008 JSON
+	FormatSingBox = "singbox" // sing-box JSON
+)
+
+// Encoder 定义编码器接口，将节点序列化为分享链接
+type Encoder interface {
+	Encode(node *model.Node) (string, error)
+}
+
+// linkEncoders 节点类型对应的编码器，与 linkParsers 中的解析器一一对应
+func linkEncoders() map[model.NodeType]Encoder {
+	return map[model.NodeType]Encoder{
+		model.TypeSS:        NewShadowsocksParser(),
+		model.TypeSSR:       NewShadowsocksRParser(),
+		model.TypeVmess:     NewVmessParser(),
+		model.TypeTrojan:    NewTrojanParser(),
+		model.TypeVless:     NewVlessParser(),
+		model.TypeHysteria2: NewHysteria2Parser(),
+		model.TypeAnyTLS:    NewAnyTLSParser(),
+		model.TypeTUIC:      NewTUICParser(),
+		model.TypeWireGuard: NewWireGuardParser(),
+		model.TypeSocks5:    NewSocksParser(),
+		model.TypeHTTP:      NewHTTPParser(),
+	}
+}
+
+// EncodeLink 将节点编码为对应协议的分享链接
+func EncodeLink(node *model.Node) (string, error) {
+	encoder, ok := linkEncoders()[node.Type]
+	if !ok {
+		return "", fmt.Errorf("no encoder found for node type: %s", node.Type)
+	}
+	return encoder.Encode(node)
+}
+
+// ParseSubscription 解析整个订阅内容，format 为 auto 时自动识别
 func ParseSubscription(content string, format string) ([]*model.Node, error) {
+	nodes, _, err := ParseSubscriptionWithFormat(content, format)
+	return nodes, err
+}
+
+// ParseSubscriptionWithFormat 同 ParseSubscription，同时返回实际使用的格式，无法识别时为空
+func ParseSubscriptionWithFormat(content string, format string) ([]*model.Node, string, error) {
 	if content == "" {
-		return nil, errors.New("empty subscription content")
+		return nil, "", errors.New("empty subscription content")
+	}
+
+	if format == FormatAuto {
+		if format = DetectFormat(content); format == "" {
+			return nil, "", errors.New("unrecognized subscription format")
+		}
 	}
-	nodes := make([]*model.Node, 0)
 
-	if format == "line" {
-		// 分割成单独的节点链接
-		links := strings.Split(content, "\n")
+	nodes, err := parseFormat(content, format)
+	return nodes, format, err
+}
 
-		// 所有支持的解析器
-		parsers := []Parser{
-			NewShadowsocksParser(),
-			NewShadowsocksRParser(),
-			NewVmessParser(),
-			NewTrojanParser(),
+// parseFormat 按指定格式解析订阅
+func parseFormat(content string, format string) ([]*model.Node, error) {
+	switch format {
+	case FormatLine:
+		return parseLines(content)
+	case FormatBase64:
+		decoded, err := utils.Base64Decode(strings.TrimSpace(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 subscription: %v", err)
 		}
+		return parseLines(decoded)
+	case FormatClashX:
+		return parseClashX(content)
+	case FormatSIP008:
+		return parseSIP008(content)
+	case FormatSingBox:
+		return parseSingBox(content)
+	}
+	return nil, fmt.Errorf("unexpect format: %s", format)
+}
+
+// parseLines 解析每行一个分享链接的订阅
+func parseLines(content string) ([]*model.Node, error) {
+	nodes := make([]*model.Node, 0)
+
+	// 分割成单独的节点链接
+	links := strings.Split(content, "\n")
+
+	parsers := linkParsers()
 
-		var parseErrors []error
-
-		for _, link := range links {
-			link = strings.TrimSpace(link)
-			if link == "" {
-				continue
-			}
-
-			node, err := parseLink(link, parsers)
-			if err != nil {
-				parseErrors = append(parseErrors, fmt.Errorf("parse link %s: %w", link, err))
-				continue
-			}
-			if node != nil {
-				nodes = append(nodes, node)
-			}
+	var parseErrors []error
+
+	for _, link := range links {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
 		}
 
-		if len(parseErrors) > 0 {
-			return nodes, fmt.Errorf("some links failed to parse: %v", parseErrors)
+		node, err := parseLink(link, parsers)
+		if err != nil {
+			parseErrors = append(parseErrors, fmt.Errorf("parse link %s: %w", link, err))
+			continue
+		}
+		if node != nil {
+			nodes = append(nodes, node)
 		}
+	}
 
-		return nodes, nil
-	} else if format == "clashx" {
-		clashConfig := ClashConfig{}
+	if len(parseErrors) > 0 {
+		return nodes, fmt.Errorf("some links failed to parse: %v", parseErrors)
+	}
 
-		err := yaml.Unmarshal([]byte(content), &clashConfig)
-		if err != nil {
-			log.Fatalf("error: %v", err)
+	return nodes, nil
+}
+
+// parseClashX 解析 Clash YAML 中的 proxies
+func parseClashX(content string) ([]*model.Node, error) {
+	nodes := make([]*model.Node, 0)
+	clashConfig := ClashConfig{}
+
+	err := yaml.Unmarshal([]byte(content), &clashConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clash config: %v", err)
+	}
+
+	// 同时按原始字段解析一遍，保留 Proxy 未建模的字段
+	rawConfig := struct {
+		Proxies []map[string]interface{} `yaml:"proxies"`
+	}{}
+	if err := yaml.Unmarshal([]byte(content), &rawConfig); err == nil && len(rawConfig.Proxies) == len(clashConfig.Proxies) {
+		for i, proxy := range clashConfig.Proxies {
+			proxy.Extra = extraProxyFields(rawConfig.Proxies[i])
 		}
-		parser := NewClashXParser()
-		for _, proxy := range clashConfig.Proxies {
-			node, _ := parser.Parse(proxy)
-			if node != nil {
-				node.AllowInsecure = proxy.SkipCertVerify
-				node.UDP = true
-				nodes = append(nodes, node)
-			}
+	}
+
+	parser := NewClashXParser()
+	for _, proxy := range clashConfig.Proxies {
+		node, _ := parser.Parse(proxy)
+		if node != nil {
+			nodes = append(nodes, node)
 		}
 	}
-	return nodes, fmt.Errorf("unexpect format: %s", format)
+
+	return nodes, nil
+}
+
+// linkParsers 所有支持的分享链接解析器
+func linkParsers() []Parser {
+	return []Parser{
+		NewShadowsocksParser(),
+		NewShadowsocksRParser(),
+		NewVmessParser(),
+		NewTrojanParser(),
+		NewVlessParser(),
+		NewHysteria2Parser(),
+		NewAnyTLSParser(),
+		NewTUICParser(),
+		NewWireGuardParser(),
+		NewSocksParser(),
+		NewHTTPParser(),
+	}
 }
 
 // 将解析单个链接的逻辑抽取成独立函数
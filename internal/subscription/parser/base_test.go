@@ -2,6 +2,22 @@ package parser
 
 import "testing"
 
+func TestParseLinesSkipsWebsiteURLs(t *testing.T) {
+	content := "https://www.example.com\n" +
+		"https://example.com/notice?id=1\n" +
+		"http://example.com/?expire=2026-12-01\n" +
+		"trojan://pass@a.example.com:443#a\n" +
+		"http://proxy.example.com:8080#http\n"
+
+	nodes, err := ParseSubscription(content, FormatLine)
+	if err == nil {
+		t.Error("ParseSubscription() should report the unmatched links")
+	}
+	if len(nodes) != 2 || nodes[0].Name != "a" || nodes[1].Name != "http" {
+		t.Errorf("ParseSubscription() = %+v, want nodes a and http", nodes)
+	}
+}
+
 func TestParseSubscription(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -15,6 +31,22 @@ func TestParseSubscription(t *testing.T) {
 			want:    0,
 			wantErr: true,
 		},
+		{
+			name: "clash tuic proxy",
+			content: `proxies:
+  - name: tuic
+    type: tuic
+    server: example.com
+    port: 443
+    uuid: uuid-1
+    password: pass
+    congestion-controller: bbr
+    udp-relay-mode: native
+    reduce-rtt: true
+`,
+			want:    1,
+			wantErr: false,
+		},
 		// 添加更多测试用例
 	}
 
@@ -2,7 +2,9 @@
 package parser
 
 import (
+	"fmt"
 	"goconverter/internal/subscription/model"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -26,31 +28,108 @@ type ClashConfig struct {
 	Secret      string       `yaml:"secret"`       // API密钥
 }
 
-// Proxy 定义单个代理服务器配置
+// Proxy 定义单个代理服务器配置，字段与 mihomo 保持一致
 type Proxy struct {
-	Name     string `yaml:"name"`             // 代理名称
-	Type     string `yaml:"type"`             // 代理类型：ss/ssr/vmess/trojan/http
-	Server   string `yaml:"server"`           // 服务器地址
-	Port     int    `yaml:"port"`             // 端口号
-	Password string `yaml:"password"`         // 密码
-	UUID     string `yaml:"uuid,omitempty"`   // UUID(VMess)
-	Cipher   string `yaml:"cipher,omitempty"` // 加密方式
-	UDP      bool   `yaml:"udp,omitempty"`    // 是否启用UDP
+	Name     string `yaml:"name"`               // 代理名称
+	Type     string `yaml:"type"`               // 代理类型：ss/ssr/vmess/trojan/http
+	Server   string `yaml:"server"`             // 服务器地址
+	Port     int    `yaml:"port"`               // 端口号
+	Username string `yaml:"username,omitempty"` // 用户名(SOCKS5/HTTP)
+	Password string `yaml:"password"`           // 密码
+	UUID     string `yaml:"uuid,omitempty"`     // UUID(VMess)
+	AlterID  int    `yaml:"alterId,omitempty"`  // AlterID(VMess)
+	Cipher   string `yaml:"cipher,omitempty"`   // 加密方式
+	UDP      bool   `yaml:"udp,omitempty"`      // 是否启用UDP
 
 	// TLS相关配置
-	TLS            bool     `yaml:"tls,omitempty"`    // 是否启用TLS
-	SkipCertVerify bool     `yaml:"skip-cert-verify"` // 是否跳过证书验证
-	Alpn           []string `yaml:"alpn,omitempty"`   // ALPN配置
-	SNI            string   `yaml:"sni,omitempty"`    // SNI配置
+	TLS               bool         `yaml:"tls,omitempty"`                // 是否启用TLS
+	SkipCertVerify    bool         `yaml:"skip-cert-verify"`             // 是否跳过证书验证
+	Alpn              []string     `yaml:"alpn,omitempty"`               // ALPN配置
+	SNI               string       `yaml:"sni,omitempty"`                // SNI配置
+	ServerName        string       `yaml:"servername,omitempty"`         // SNI配置(VMess/VLESS)
+	ClientFingerprint string       `yaml:"client-fingerprint,omitempty"` // uTLS 指纹
+	Fingerprint       string       `yaml:"fingerprint,omitempty"`        // 证书指纹
+	RealityOpts       *RealityOpts `yaml:"reality-opts,omitempty"`       // REALITY配置
 
 	// 传输层配置
 	Network   string            `yaml:"network,omitempty"`    // 传输协议：ws/h2/grpc
-	WsPath    string            `yaml:"ws-path,omitempty"`    // WebSocket路径
-	WsHeaders map[string]string `yaml:"ws-headers,omitempty"` // WebSocket请求头
+	WsPath    string            `yaml:"ws-path,omitempty"`    // WebSocket路径(旧版写法)
+	WsHeaders map[string]string `yaml:"ws-headers,omitempty"` // WebSocket请求头(旧版写法)
+	WsOpts    *WsOpts           `yaml:"ws-opts,omitempty"`    // WebSocket配置
+	H2Opts    *H2Opts           `yaml:"h2-opts,omitempty"`    // HTTP/2配置
+	GrpcOpts  *GrpcOpts         `yaml:"grpc-opts,omitempty"`  // gRPC配置
+
+	// VLESS配置
+	Flow           string `yaml:"flow,omitempty"`            // 流控
+	PacketEncoding string `yaml:"packet-encoding,omitempty"` // UDP 包编码
 
 	// 插件配置
-	Plugin     string                 `yaml:"plugin,omitempty"`      // 插件名称
-	PluginOpts map[string]interface{} `yaml:"plugin-opts,omitempty"` // 插件配置
+	Plugin     string                 `yaml:"plugin,omitempty"`       // 插件名称
+	PluginOpts map[string]interface{} `yaml:"plugin-opts,omitempty"`  // 插件配置
+	UDPOverTCP bool                   `yaml:"udp-over-tcp,omitempty"` // UDP over TCP
+
+	// SSR配置
+	Protocol      string `yaml:"protocol,omitempty"`       // SSR协议
+	ProtocolParam string `yaml:"protocol-param,omitempty"` // SSR协议参数
+	Obfs          string `yaml:"obfs,omitempty"`           // SSR/Hysteria2 混淆
+	ObfsParam     string `yaml:"obfs-param,omitempty"`     // SSR混淆参数
+
+	// Hysteria2配置
+	ObfsPassword string      `yaml:"obfs-password,omitempty"` // 混淆密码
+	Ports        string      `yaml:"ports,omitempty"`         // 端口跳跃范围
+	Up           interface{} `yaml:"up,omitempty"`            // 上行带宽，数字或 "50 Mbps"
+	Down         interface{} `yaml:"down,omitempty"`          // 下行带宽
+
+	// TUIC配置
+	CongestionController string `yaml:"congestion-controller,omitempty"` // 拥塞控制
+	UDPRelayMode         string `yaml:"udp-relay-mode,omitempty"`        // UDP 转发模式
+	DisableSNI           bool   `yaml:"disable-sni,omitempty"`           // 是否禁用 SNI
+	ReduceRTT            bool   `yaml:"reduce-rtt,omitempty"`            // 是否启用 0-RTT
+
+	// WireGuard配置
+	PrivateKey   string      `yaml:"private-key,omitempty"`    // 本地私钥
+	PublicKey    string      `yaml:"public-key,omitempty"`     // 对端公钥
+	PreSharedKey string      `yaml:"pre-shared-key,omitempty"` // 预共享密钥
+	IP           string      `yaml:"ip,omitempty"`             // 本地 IPv4 地址
+	IPv6         string      `yaml:"ipv6,omitempty"`           // 本地 IPv6 地址
+	Reserved     interface{} `yaml:"reserved,omitempty"`       // [1, 2, 3] 或 base64 字符串
+	MTU          int         `yaml:"mtu,omitempty"`            // MTU
+	AllowedIPs   []string    `yaml:"allowed-ips,omitempty"`    // 允许的 IP 段
+
+	// 通用配置
+	DialerProxy string                 `yaml:"dialer-proxy,omitempty"` // 前置代理
+	IPVersion   string                 `yaml:"ip-version,omitempty"`   // 出站 IP 版本
+	TFO         bool                   `yaml:"tfo,omitempty"`          // TCP Fast Open
+	Smux        map[string]interface{} `yaml:"smux,omitempty"`         // 多路复用配置
+
+	// Extra 其余未建模的字段，由 parseClashX 填充
+	Extra map[string]interface{} `yaml:"-"`
+}
+
+// WsOpts WebSocket 传输配置
+type WsOpts struct {
+	Path                string            `yaml:"path,omitempty"`
+	Headers             map[string]string `yaml:"headers,omitempty"`
+	MaxEarlyData        int               `yaml:"max-early-data,omitempty"`
+	EarlyDataHeaderName string            `yaml:"early-data-header-name,omitempty"`
+	V2rayHTTPUpgrade    bool              `yaml:"v2ray-http-upgrade,omitempty"`
+}
+
+// H2Opts HTTP/2 传输配置
+type H2Opts struct {
+	Host []string `yaml:"host,omitempty"`
+	Path string   `yaml:"path,omitempty"`
+}
+
+// GrpcOpts gRPC 传输配置
+type GrpcOpts struct {
+	GrpcServiceName string `yaml:"grpc-service-name,omitempty"`
+}
+
+// RealityOpts REALITY 配置
+type RealityOpts struct {
+	PublicKey string `yaml:"public-key,omitempty"`
+	ShortID   string `yaml:"short-id,omitempty"`
 }
 
 // ProxyGroup 定义代理分组配置
@@ -95,21 +174,140 @@ func (p *ClashXParser) Parse(proxy *Proxy) (*model.Node, error) {
 
 	settings := map[string]string{
 		"uuid":             proxy.UUID,
-		"alterId":          "",
+		"alterId":          strconv.Itoa(proxy.AlterID),
 		"network":          proxy.Network,
 		"tls":              strconv.FormatBool(proxy.TLS),
 		"skip-cert-verify": strconv.FormatBool(proxy.SkipCertVerify),
 	}
 
-	return &model.Node{
+	node := &model.Node{
 		Type:     model.NodeType(proxy.Type),
 		Name:     proxy.Name,
 		Server:   proxy.Server,
 		Port:     proxy.Port,
+		Username: proxy.Username,
 		Password: proxy.Password,
-		Protocol: "",
+		UUID:     proxy.UUID,
+		AlterID:  proxy.AlterID,
+		Cipher:   proxy.Cipher,
 		Settings: settings,
 		UDP:      proxy.UDP,
-		ALPN:     proxy.Alpn,
-	}, nil
+
+		TLS:               proxy.TLS,
+		AllowInsecure:     proxy.SkipCertVerify,
+		ALPN:              proxy.Alpn,
+		SNI:               defaultIfEmpty(proxy.ServerName, proxy.SNI),
+		ClientFingerprint: proxy.ClientFingerprint,
+		CertFingerprint:   proxy.Fingerprint,
+
+		Network:        proxy.Network,
+		WsPath:         proxy.WsPath,
+		WsHeaders:      proxy.WsHeaders,
+		Flow:           proxy.Flow,
+		PacketEncoding: proxy.PacketEncoding,
+
+		Plugin:     proxy.Plugin,
+		UDPOverTCP: proxy.UDPOverTCP,
+
+		Protocol:      proxy.Protocol,
+		ProtocolParam: proxy.ProtocolParam,
+		Obfs:          proxy.Obfs,
+		ObfsParam:     proxy.ObfsParam,
+
+		ObfsPassword: proxy.ObfsPassword,
+		Ports:        proxy.Ports,
+
+		CongestionControl: proxy.CongestionController,
+		UDPRelayMode:      proxy.UDPRelayMode,
+		DisableSNI:        proxy.DisableSNI,
+		ReduceRTT:         proxy.ReduceRTT,
+
+		PrivateKey:   proxy.PrivateKey,
+		PublicKey:    proxy.PublicKey,
+		PreSharedKey: proxy.PreSharedKey,
+		IP:           proxy.IP,
+		IPv6:         proxy.IPv6,
+		MTU:          proxy.MTU,
+		AllowedIPs:   proxy.AllowedIPs,
+
+		DialerProxy: proxy.DialerProxy,
+		IPVersion:   proxy.IPVersion,
+		TFO:         proxy.TFO,
+		Smux:        proxy.Smux,
+		Extra:       proxy.Extra,
+	}
+
+	if proxy.Up != nil {
+		node.Up = fmt.Sprint(proxy.Up)
+	}
+	if proxy.Down != nil {
+		node.Down = fmt.Sprint(proxy.Down)
+	}
+
+	if len(proxy.PluginOpts) > 0 {
+		node.PluginOpts = make(map[string]string, len(proxy.PluginOpts))
+		for key, value := range proxy.PluginOpts {
+			node.PluginOpts[key] = fmt.Sprint(value)
+		}
+	}
+
+	if opts := proxy.WsOpts; opts != nil {
+		node.WsPath = opts.Path
+		node.WsHeaders = opts.Headers
+		node.WsMaxEarlyData = opts.MaxEarlyData
+		node.WsEarlyDataHeader = opts.EarlyDataHeaderName
+		if opts.V2rayHTTPUpgrade {
+			node.Network = "httpupgrade"
+		}
+	}
+	if opts := proxy.H2Opts; opts != nil {
+		node.H2Host = opts.Host
+		node.H2Path = opts.Path
+	}
+	if opts := proxy.GrpcOpts; opts != nil {
+		node.GrpcServiceName = opts.GrpcServiceName
+	}
+	if opts := proxy.RealityOpts; opts != nil {
+		node.RealityPublicKey = opts.PublicKey
+		node.RealityShortID = opts.ShortID
+	}
+
+	switch reserved := proxy.Reserved.(type) {
+	case string:
+		node.Reserved = parseReserved(reserved)
+	case []interface{}:
+		for _, b := range reserved {
+			node.Reserved = append(node.Reserved, parseInt(fmt.Sprint(b)))
+		}
+	}
+
+	return node, nil
+}
+
+// clashProxyKeys Proxy 结构体已建模的 YAML 字段
+var clashProxyKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Proxy{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// extraProxyFields 返回 Proxy 未建模的字段，保证 Clash 到 Clash 的转换不丢字段
+func extraProxyFields(raw map[string]interface{}) map[string]interface{} {
+	var extra map[string]interface{}
+	for key, value := range raw {
+		if clashProxyKeys[key] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = value
+	}
+	return extra
 }
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+// TestClashRoundTrip 解析后重新输出的 Clash proxies 必须与输入一致
+func TestClashRoundTrip(t *testing.T) {
+	content, err := os.ReadFile("testdata/clash_roundtrip.yaml")
+	if err != nil {
+		t.Fatalf("读取测试数据失败: %v", err)
+	}
+
+	nodes, err := ParseSubscription(string(content), FormatClashX)
+	if err != nil {
+		t.Fatalf("ParseSubscription() error = %v", err)
+	}
+
+	var golden struct {
+		Proxies []map[string]interface{} `yaml:"proxies"`
+	}
+	if err := yaml.Unmarshal(content, &golden); err != nil {
+		t.Fatalf("解析测试数据失败: %v", err)
+	}
+	if len(nodes) != len(golden.Proxies) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(golden.Proxies))
+	}
+
+	for i, node := range nodes {
+		want := golden.Proxies[i]
+		t.Run(node.Name, func(t *testing.T) {
+			// 经过一次 YAML 序列化，统一数字、切片等类型
+			data, err := yaml.Marshal(node.ToClash())
+			if err != nil {
+				t.Fatalf("yaml.Marshal() error = %v", err)
+			}
+			var got map[string]interface{}
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatalf("yaml.Unmarshal() error = %v", err)
+			}
+
+			for key, value := range want {
+				if !reflect.DeepEqual(got[key], value) {
+					t.Errorf("%s = %#v, want %#v", key, got[key], value)
+				}
+			}
+			for key, value := range got {
+				if _, ok := want[key]; !ok {
+					t.Errorf("unexpected %s = %#v", key, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// internal/subscription/parser/detect.go
+package parser
+
+import (
+	"encoding/json"
+	"goconverter/internal/utils"
+	"regexp"
+	"strings"
+)
+
+var clashProxiesKey = regexp.MustCompile(`(?m)^proxies:`)
+
+// DetectFormat 识别订阅内容的格式，无法识别时返回空字符串
+func DetectFormat(content string) string {
+	content = strings.TrimSpace(strings.TrimPrefix(content, "\ufeff"))
+	if content == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(content, "{") {
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(content), &probe); err == nil {
+			if _, ok := probe["servers"]; ok {
+				return FormatSIP008
+			}
+			if _, ok := probe["outbounds"]; ok {
+				return FormatSingBox
+			}
+			if _, ok := probe["endpoints"]; ok {
+				return FormatSingBox
+			}
+		}
+	}
+
+	if clashProxiesKey.MatchString(content) {
+		return FormatClashX
+	}
+
+	if isLinkList(content) {
+		return FormatLine
+	}
+
+	if decoded, err := utils.Base64Decode(content); err == nil && isLinkList(decoded) {
+		return FormatBase64
+	}
+
+	return ""
+}
+
+// isLinkList 判断第一行有效内容是否为分享链接
+func isLinkList(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		scheme, _, found := strings.Cut(line, "://")
+		return found && scheme != "" && !strings.ContainsAny(scheme, " \t{}")
+	}
+	return false
+}
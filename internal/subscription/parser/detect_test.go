@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	links := "trojan://pass@a.example.com:443#a\r\nvless://uuid@b.example.com:443?security=tls#b\r\n"
+
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		wantNodes int
+	}{
+		{
+			name:      "clash yaml",
+			content:   "port: 7890\nproxies:\n  - {name: a, type: trojan, server: a.example.com, port: 443, password: pass}\n",
+			want:      FormatClashX,
+			wantNodes: 1,
+		},
+		{
+			name:      "plain link list",
+			content:   links,
+			want:      FormatLine,
+			wantNodes: 2,
+		},
+		{
+			name:      "base64 std padded",
+			content:   base64.StdEncoding.EncodeToString([]byte(links)),
+			want:      FormatBase64,
+			wantNodes: 2,
+		},
+		{
+			name:      "base64 url unpadded with line breaks",
+			content:   wrap(base64.RawURLEncoding.EncodeToString([]byte(links)), 20),
+			want:      FormatBase64,
+			wantNodes: 2,
+		},
+		{
+			name:      "sip008",
+			content:   `{"version":1,"servers":[{"id":"1","remarks":"ss","server":"a.example.com","server_port":8388,"password":"p","method":"aes-256-gcm"}]}`,
+			want:      FormatSIP008,
+			wantNodes: 1,
+		},
+		{
+			name: "sing-box",
+			content: `{"outbounds":[
+				{"type":"selector","tag":"proxy","outbounds":["vless"]},
+				{"type":"vless","tag":"vless","server":"a.example.com","server_port":443,"uuid":"uuid","flow":"xtls-rprx-vision",
+				 "tls":{"enabled":true,"server_name":"www.microsoft.com","utls":{"enabled":true,"fingerprint":"chrome"},"reality":{"enabled":true,"public_key":"pbk","short_id":"sid"}}},
+				{"type":"hysteria2","tag":"hy2","server":"b.example.com","server_port":443,"password":"p","obfs":{"type":"salamander","password":"o"},"tls":{"enabled":true}},
+				{"type":"direct","tag":"direct"}
+			]}`,
+			want:      FormatSingBox,
+			wantNodes: 2,
+		},
+		{
+			name:    "unknown",
+			content: "hello world",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.content); got != tt.want {
+				t.Fatalf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+			nodes, format, err := ParseSubscriptionWithFormat(tt.content, FormatAuto)
+			if format != tt.want {
+				t.Errorf("ParseSubscriptionWithFormat() format = %q, want %q", format, tt.want)
+			}
+			if tt.want == "" {
+				if err == nil {
+					t.Error("ParseSubscriptionWithFormat() should fail for unknown content")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSubscriptionWithFormat() error = %v", err)
+			}
+			if len(nodes) != tt.wantNodes {
+				t.Errorf("ParseSubscriptionWithFormat() got %d nodes, want %d", len(nodes), tt.wantNodes)
+			}
+		})
+	}
+}
+
+func wrap(s string, width int) string {
+	var wrapped string
+	for len(s) > width {
+		wrapped += s[:width] + "\r\n"
+		s = s[width:]
+	}
+	return wrapped + s
+}
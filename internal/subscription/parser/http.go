@@ -0,0 +1,80 @@
+// internal/subscription/parser/http.go
+package parser
+
+import (
+	"goconverter/internal/subscription/model"
+	"net/url"
+	"strings"
+)
+
+type HTTPParser struct{}
+
+func NewHTTPParser() *HTTPParser {
+	return &HTTPParser{}
+}
+
+// Match 只接受带认证信息或显式端口、没有路径且只有代理参数的地址，
+// 避免把订阅中的官网、公告等网址当作节点
+func (p *HTTPParser) Match(link string) bool {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	if u.Path != "" && u.Path != "/" {
+		return false
+	}
+	for key := range u.Query() {
+		if key != "sni" && key != "skip-cert-verify" {
+			return false
+		}
+	}
+	return u.User != nil || u.Port() != ""
+}
+
+// Parse 解析 http(s)://user:pass@host:port#name，https 表示启用 TLS
+func (p *HTTPParser) Parse(link string) (*model.Node, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	node := &model.Node{
+		Type:          model.TypeHTTP,
+		Name:          u.Fragment,
+		Server:        u.Hostname(),
+		Port:          parseInt(u.Port()),
+		TLS:           u.Scheme == "https",
+		SNI:           query.Get("sni"),
+		AllowInsecure: parseBool(query.Get("skip-cert-verify")),
+		Settings:      make(map[string]string),
+	}
+	node.Username, node.Password = parseUserinfo(u)
+	if node.Port == 0 {
+		node.Port = 80
+		if node.TLS {
+			node.Port = 443
+		}
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+
+	return node, nil
+}
+
+// Encode 生成 http:// 或 https:// 分享链接
+func (p *HTTPParser) Encode(node *model.Node) (string, error) {
+	scheme := "http"
+	if node.TLS {
+		scheme = "https"
+	}
+	query := url.Values{}
+	setParam(query, "sni", node.SNI)
+	setBoolParam(query, "skip-cert-verify", node.AllowInsecure)
+
+	return buildLink(scheme, encodeUserinfo(node), node, query), nil
+}
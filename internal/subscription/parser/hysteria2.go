@@ -0,0 +1,118 @@
+// internal/subscription/parser/hysteria2.go
+package parser
+
+import (
+	"goconverter/internal/subscription/model"
+	"net/url"
+	"strings"
+)
+
+type Hysteria2Parser struct{}
+
+func NewHysteria2Parser() *Hysteria2Parser {
+	return &Hysteria2Parser{}
+}
+
+func (p *Hysteria2Parser) Match(link string) bool {
+	return strings.HasPrefix(link, "hysteria2://") || strings.HasPrefix(link, "hy2://")
+}
+
+// Parse 解析 hysteria2://auth@host:port/?sni=...&obfs=salamander&mport=20000-30000#name
+func (p *Hysteria2Parser) Parse(link string) (*model.Node, error) {
+	link, ports := cutMultiPort(link)
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	node := &model.Node{
+		Type:            model.TypeHysteria2,
+		Name:            u.Fragment,
+		Server:          u.Hostname(),
+		Port:            parseInt(u.Port()),
+		Password:        userinfoPassword(u),
+		SNI:             query.Get("sni"),
+		AllowInsecure:   parseBool(query.Get("insecure")),
+		Obfs:            query.Get("obfs"),
+		ObfsPassword:    query.Get("obfs-password"),
+		Ports:           defaultIfEmpty(query.Get("mport"), ports),
+		Up:              query.Get("up"),
+		Down:            query.Get("down"),
+		CertFingerprint: query.Get("pinSHA256"),
+		UDP:             true,
+		Settings:        make(map[string]string),
+	}
+	if node.Port == 0 {
+		node.Port = 443
+	}
+	if alpn := query.Get("alpn"); alpn != "" {
+		node.ALPN = strings.Split(alpn, ",")
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+
+	return node, nil
+}
+
+// Encode 生成 hysteria2:// 分享链接
+func (p *Hysteria2Parser) Encode(node *model.Node) (string, error) {
+	query := url.Values{}
+	setParam(query, "sni", node.SNI)
+	setBoolParam(query, "insecure", node.AllowInsecure)
+	setParam(query, "obfs", node.Obfs)
+	setParam(query, "obfs-password", node.ObfsPassword)
+	setParam(query, "mport", node.Ports)
+	setParam(query, "up", node.Up)
+	setParam(query, "down", node.Down)
+	setParam(query, "pinSHA256", node.CertFingerprint)
+	setParam(query, "alpn", strings.Join(node.ALPN, ","))
+
+	return buildLink("hysteria2", url.User(node.Password), node, query), nil
+}
+
+// cutMultiPort 处理 host:443,20000-30000 形式的端口跳跃写法
+func cutMultiPort(link string) (string, string) {
+	start := strings.Index(link, "://") + 3
+	end := len(link)
+	if idx := strings.IndexAny(link[start:], "/?#"); idx != -1 {
+		end = start + idx
+	}
+	authority := link[start:end]
+	hostStart := strings.LastIndex(authority, "@") + 1
+
+	idx := strings.Index(authority[hostStart:], ",")
+	if idx == -1 {
+		return link, ""
+	}
+	hostPort := authority[hostStart : hostStart+idx]
+	ports := authority[hostStart+idx+1:]
+	// 只有端口跳跃时，第一个端口范围作为默认端口
+	if !strings.Contains(hostPort[strings.LastIndex(hostPort, "]")+1:], ":") {
+		first := strings.SplitN(strings.SplitN(ports, ",", 2)[0], "-", 2)[0]
+		hostPort += ":" + first
+	}
+
+	return link[:start] + authority[:hostStart] + hostPort + link[end:], ports
+}
+
+// userinfoPassword 读取 userinfo 中的密码，兼容 user:pass 与单独的 auth
+func userinfoPassword(u *url.URL) string {
+	if u.User == nil {
+		return ""
+	}
+	if password, ok := u.User.Password(); ok {
+		return u.User.Username() + ":" + password
+	}
+	return u.User.Username()
+}
+
+// defaultIfEmpty 如果字符串为空则返回默认值
+func defaultIfEmpty(str, def string) string {
+	if strings.TrimSpace(str) == "" {
+		return def
+	}
+	return str
+}
@@ -6,7 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"net"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +24,11 @@ func (p *ShadowsocksParser) Match(link string) bool {
 	return strings.HasPrefix(link, "ss://")
 }
 
+// Parse 支持 SIP002 与旧版全 base64 两种格式
+//
+//	ss://base64(method:pass)@host:port/?plugin=obfs-local%3Bobfs%3Dhttp#name
+//	ss://method:pass@[::1]:8388#name
+//	ss://base64(method:pass@host:port)#name
 func (p *ShadowsocksParser) Parse(link string) (*model.Node, error) {
 	// 移除 "ss://" 前缀
 	link = strings.TrimPrefix(link, "ss://")
@@ -29,39 +38,193 @@ func (p *ShadowsocksParser) Parse(link string) (*model.Node, error) {
 	if idx := strings.Index(link, "#"); idx != -1 {
 		name = link[idx+1:]
 		link = link[:idx]
-		name, _ = url.QueryUnescape(name)
+		name, _ = url.PathUnescape(name)
 	}
 
-	// 解析主体部分
-	decodedBytes, err := base64.RawURLEncoding.DecodeString(link)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode ss link: %v", err)
+	// 旧版格式整体 base64，解码后再按 SIP002 处理
+	if !strings.Contains(link, "@") {
+		body, query, _ := strings.Cut(link, "?")
+		decoded, err := utils.Base64Decode(strings.TrimSuffix(body, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ss link: %v", err)
+		}
+		link = decoded
+		if query != "" {
+			link += "/?" + query
+		}
 	}
-	decoded := string(decodedBytes)
 
-	// 分离方法和密码
-	parts := strings.SplitN(decoded, "@", 2)
-	if len(parts) != 2 {
+	// 分离用户信息和服务器地址
+	at := strings.LastIndex(link, "@")
+	if at == -1 {
 		return nil, errors.New("invalid ss link format")
 	}
+	userinfo, address := link[:at], link[at+1:]
 
-	methodAndPass := strings.SplitN(parts[0], ":", 2)
-	if len(methodAndPass) != 2 {
-		return nil, errors.New("invalid method and password format")
+	method, password, err := parseSSUserinfo(userinfo)
+	if err != nil {
+		return nil, err
 	}
 
-	serverAndPort := strings.SplitN(parts[1], ":", 2)
-	if len(serverAndPort) != 2 {
-		return nil, errors.New("invalid server and port format")
+	var rawQuery string
+	if idx := strings.IndexAny(address, "/?"); idx != -1 {
+		address, rawQuery = address[:idx], strings.TrimLeft(address[idx:], "/?")
+	}
+	server, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server and port format: %v", err)
 	}
 
-	return &model.Node{
-		Type:     "ss",
+	node := &model.Node{
+		Type:     model.TypeSS,
 		Name:     name,
-		Server:   serverAndPort[0],
-		Port:     parseInt(serverAndPort[1]),
-		Protocol: methodAndPass[0],
-		Password: methodAndPass[1],
+		Server:   server,
+		Port:     parseInt(port),
+		Cipher:   method,
+		Password: password,
+		UDP:      true,
 		Settings: make(map[string]string),
-	}, nil
+	}
+	if node.Name == "" {
+		node.Name = net.JoinHostPort(server, port)
+	}
+
+	query, _ := url.ParseQuery(rawQuery)
+	if plugin := query.Get("plugin"); plugin != "" {
+		node.Plugin, node.PluginOpts = parseSSPlugin(plugin)
+	}
+
+	return node, nil
+}
+
+// Encode 生成 SIP002 格式的 ss:// 分享链接
+//
+// SS2022 的密码本身是 base64，按规范使用明文 userinfo，其余使用 base64url
+func (p *ShadowsocksParser) Encode(node *model.Node) (string, error) {
+	var user *url.Userinfo
+	if strings.HasPrefix(node.Cipher, "2022-") {
+		user = url.UserPassword(node.Cipher, node.Password)
+	} else {
+		user = url.User(base64.RawURLEncoding.EncodeToString([]byte(node.Cipher + ":" + node.Password)))
+	}
+
+	query := url.Values{}
+	if node.Plugin != "" {
+		query.Set("plugin", encodeSSPlugin(node.Plugin, node.PluginOpts))
+	}
+
+	link := buildLink("ss", user, node, query)
+	if node.Plugin != "" {
+		// SIP002 要求插件参数前有 /
+		link = strings.Replace(link, "?", "/?", 1)
+	}
+	return link, nil
+}
+
+// parseSSUserinfo 解析 method:password，兼容 base64 与 SS2022 要求的明文写法
+func parseSSUserinfo(userinfo string) (string, string, error) {
+	if unescaped, err := url.PathUnescape(userinfo); err == nil {
+		userinfo = unescaped
+	}
+
+	// base64 字母表不含冒号，出现冒号即为明文
+	if method, password, found := strings.Cut(userinfo, ":"); found {
+		return method, password, nil
+	}
+
+	decoded, err := utils.Base64Decode(userinfo)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode ss userinfo: %v", err)
+	}
+	method, password, found := strings.Cut(decoded, ":")
+	if !found {
+		return "", "", errors.New("invalid method and password format")
+	}
+	return method, password, nil
+}
+
+// parseSSPlugin 将 SIP003 插件参数转换为 Clash 的 plugin/plugin-opts
+//
+//	obfs-local;obfs=http;obfs-host=example.com  -> obfs {mode: http, host: example.com}
+//	v2ray-plugin;tls;host=example.com;path=/ws  -> v2ray-plugin {mode: websocket, tls: true, ...}
+func parseSSPlugin(value string) (string, map[string]string) {
+	parts := strings.Split(value, ";")
+	name := parts[0]
+	args := make(map[string]string)
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			val = "true"
+		}
+		args[key] = val
+	}
+
+	opts := make(map[string]string)
+	switch name {
+	case "obfs-local", "simple-obfs", "obfs":
+		name = "obfs"
+		opts["mode"] = args["obfs"]
+		if host, ok := args["obfs-host"]; ok {
+			opts["host"] = host
+		}
+	case "v2ray-plugin":
+		opts["mode"] = defaultIfEmpty(args["mode"], "websocket")
+		for _, key := range []string{"host", "path"} {
+			if val, ok := args[key]; ok {
+				opts[key] = val
+			}
+		}
+		if args["tls"] == "true" {
+			opts["tls"] = "true"
+		}
+		if mux, ok := args["mux"]; ok {
+			if n, err := strconv.Atoi(mux); err == nil {
+				mux = strconv.FormatBool(n > 0)
+			}
+			opts["mux"] = mux
+		}
+	default:
+		opts = args
+	}
+
+	return name, opts
+}
+
+// encodeSSPlugin 与 parseSSPlugin 相反，生成 SIP003 插件参数
+func encodeSSPlugin(name string, opts map[string]string) string {
+	parts := make([]string, 0)
+	switch name {
+	case "obfs":
+		name = "obfs-local"
+		parts = append(parts, "obfs="+opts["mode"])
+		if host, ok := opts["host"]; ok {
+			parts = append(parts, "obfs-host="+host)
+		}
+	case "v2ray-plugin":
+		if mode := opts["mode"]; mode != "" && mode != "websocket" {
+			parts = append(parts, "mode="+mode)
+		}
+		if opts["tls"] == "true" {
+			parts = append(parts, "tls")
+		}
+		for _, key := range []string{"host", "path", "mux"} {
+			if val, ok := opts[key]; ok {
+				parts = append(parts, key+"="+val)
+			}
+		}
+	default:
+		keys := make([]string, 0, len(opts))
+		for key := range opts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parts = append(parts, key+"="+opts[key])
+		}
+	}
+
+	return strings.Join(append([]string{name}, parts...), ";")
 }
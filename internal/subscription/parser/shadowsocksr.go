@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"goconverter/internal/subscription/model"
+	"strconv"
 	"strings"
 )
 
@@ -91,6 +92,34 @@ func (p *ShadowsocksRParser) Parse(link string) (*model.Node, error) {
 	return node, nil
 }
 
+// Encode 生成 ssr:// 分享链接
+//
+//	ssr://base64(host:port:protocol:method:obfs:base64(password)/?obfsparam=...&protoparam=...&remarks=...&group=...)
+func (p *ShadowsocksRParser) Encode(node *model.Node) (string, error) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	main := strings.Join([]string{
+		node.Server,
+		strconv.Itoa(node.Port),
+		node.Protocol,
+		node.Cipher,
+		node.Obfs,
+		encode(node.Password),
+	}, ":")
+	params := []string{
+		"obfsparam=" + encode(node.ObfsParam),
+		"protoparam=" + encode(node.ProtocolParam),
+		"remarks=" + encode(node.Name),
+	}
+	if node.Group != "" {
+		params = append(params, "group="+encode(node.Group))
+	}
+
+	return "ssr://" + encode(main+"/?"+strings.Join(params, "&")), nil
+}
+
 // 解析SSR参数
 func parseSSRParams(params string) (map[string]string, error) {
 	result := make(map[string]string)
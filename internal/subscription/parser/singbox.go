@@ -0,0 +1,268 @@
+// internal/subscription/parser/singbox.go
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"strings"
+)
+
+// SingBoxConfig sing-box 配置中与节点相关的部分
+type SingBoxConfig struct {
+	Outbounds []*SingBoxOutbound `json:"outbounds"`
+	Endpoints []*SingBoxOutbound `json:"endpoints"` // 1.11 起 WireGuard 迁移到 endpoints
+}
+
+// SingBoxOutbound sing-box 出站配置
+type SingBoxOutbound struct {
+	Type        string   `json:"type"`
+	Tag         string   `json:"tag"`
+	Server      string   `json:"server"`
+	ServerPort  int      `json:"server_port"`
+	ServerPorts []string `json:"server_ports"`
+	Username    string   `json:"username"`
+	Password    string   `json:"password"`
+	Method      string   `json:"method"`
+	Plugin      string   `json:"plugin"`
+	PluginOpts  string   `json:"plugin_opts"`
+	UUID        string   `json:"uuid"`
+	AlterID     int      `json:"alter_id"`
+	Security    string   `json:"security"`
+	Flow        string   `json:"flow"`
+	Network     string   `json:"network"`
+
+	// Hysteria2
+	UpMbps   int `json:"up_mbps"`
+	DownMbps int `json:"down_mbps"`
+	Obfs     *struct {
+		Type     string `json:"type"`
+		Password string `json:"password"`
+	} `json:"obfs"`
+
+	// TUIC
+	CongestionControl string `json:"congestion_control"`
+	UDPRelayMode      string `json:"udp_relay_mode"`
+	ZeroRTTHandshake  bool   `json:"zero_rtt_handshake"`
+
+	// WireGuard
+	LocalAddress  []string                `json:"local_address"`
+	Address       []string                `json:"address"`
+	PrivateKey    string                  `json:"private_key"`
+	PeerPublicKey string                  `json:"peer_public_key"`
+	PreSharedKey  string                  `json:"pre_shared_key"`
+	Reserved      []int                   `json:"reserved"`
+	MTU           int                     `json:"mtu"`
+	Peers         []*SingBoxWireGuardPeer `json:"peers"`
+
+	TLS       *SingBoxTLS       `json:"tls"`
+	Transport *SingBoxTransport `json:"transport"`
+}
+
+// SingBoxWireGuardPeer endpoints 中的 WireGuard 对端
+type SingBoxWireGuardPeer struct {
+	Address      string   `json:"address"`
+	Port         int      `json:"port"`
+	PublicKey    string   `json:"public_key"`
+	PreSharedKey string   `json:"pre_shared_key"`
+	AllowedIPs   []string `json:"allowed_ips"`
+	Reserved     []int    `json:"reserved"`
+}
+
+// SingBoxTLS sing-box TLS 配置
+type SingBoxTLS struct {
+	Enabled     bool     `json:"enabled"`
+	ServerName  string   `json:"server_name"`
+	Insecure    bool     `json:"insecure"`
+	DisableSNI  bool     `json:"disable_sni"`
+	ALPN        []string `json:"alpn"`
+	Certificate string   `json:"certificate"`
+	UTLS        *struct {
+		Enabled     bool   `json:"enabled"`
+		Fingerprint string `json:"fingerprint"`
+	} `json:"utls"`
+	Reality *struct {
+		Enabled   bool   `json:"enabled"`
+		PublicKey string `json:"public_key"`
+		ShortID   string `json:"short_id"`
+	} `json:"reality"`
+}
+
+// SingBoxTransport sing-box 传输层配置
+type SingBoxTransport struct {
+	Type        string            `json:"type"`
+	Path        string            `json:"path"`
+	Headers     map[string]string `json:"headers"`
+	Host        json.RawMessage   `json:"host"` // http 为数组，httpupgrade 为字符串
+	ServiceName string            `json:"service_name"`
+}
+
+// parseSingBox 解析 sing-box 配置中的代理出站
+func parseSingBox(content string) ([]*model.Node, error) {
+	var config SingBoxConfig
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sing-box config: %v", err)
+	}
+
+	nodes := make([]*model.Node, 0, len(config.Outbounds))
+	for _, outbound := range append(config.Outbounds, config.Endpoints...) {
+		if node := parseSingBoxOutbound(outbound); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// parseSingBoxOutbound 转换单个出站，selector/direct 等非代理出站返回 nil
+func parseSingBoxOutbound(outbound *SingBoxOutbound) *model.Node {
+	node := &model.Node{
+		Name:     outbound.Tag,
+		Server:   outbound.Server,
+		Port:     outbound.ServerPort,
+		Username: outbound.Username,
+		Password: outbound.Password,
+		UUID:     outbound.UUID,
+		UDP:      outbound.Network != "tcp",
+		Settings: make(map[string]string),
+	}
+
+	switch outbound.Type {
+	case "shadowsocks":
+		node.Type = model.TypeSS
+		node.Cipher = outbound.Method
+		if outbound.Plugin != "" {
+			node.Plugin = outbound.Plugin
+			node.PluginOpts = parsePluginOpts(outbound.PluginOpts)
+		}
+	case "vmess":
+		node.Type = model.TypeVmess
+		node.AlterID = outbound.AlterID
+		node.Cipher = outbound.Security
+	case "vless":
+		node.Type = model.TypeVless
+		node.Flow = outbound.Flow
+	case "trojan":
+		node.Type = model.TypeTrojan
+	case "hysteria2":
+		node.Type = model.TypeHysteria2
+		if outbound.Obfs != nil {
+			node.Obfs = outbound.Obfs.Type
+			node.ObfsPassword = outbound.Obfs.Password
+		}
+		if len(outbound.ServerPorts) > 0 {
+			node.Ports = strings.ReplaceAll(strings.Join(outbound.ServerPorts, ","), ":", "-")
+		}
+		if outbound.UpMbps > 0 {
+			node.Up = fmt.Sprintf("%d", outbound.UpMbps)
+		}
+		if outbound.DownMbps > 0 {
+			node.Down = fmt.Sprintf("%d", outbound.DownMbps)
+		}
+	case "tuic":
+		node.Type = model.TypeTUIC
+		node.CongestionControl = outbound.CongestionControl
+		node.UDPRelayMode = outbound.UDPRelayMode
+		node.ReduceRTT = outbound.ZeroRTTHandshake
+	case "anytls":
+		node.Type = model.TypeAnyTLS
+	case "socks":
+		node.Type = model.TypeSocks5
+	case "http":
+		node.Type = model.TypeHTTP
+	case "wireguard":
+		node.Type = model.TypeWireGuard
+		parseSingBoxWireGuard(node, outbound)
+	default:
+		return nil
+	}
+
+	if tls := outbound.TLS; tls != nil && tls.Enabled {
+		node.TLS = true
+		node.SNI = tls.ServerName
+		node.AllowInsecure = tls.Insecure
+		node.DisableSNI = tls.DisableSNI
+		node.ALPN = tls.ALPN
+		if tls.UTLS != nil && tls.UTLS.Enabled {
+			node.ClientFingerprint = tls.UTLS.Fingerprint
+		}
+		if tls.Reality != nil && tls.Reality.Enabled {
+			node.RealityPublicKey = tls.Reality.PublicKey
+			node.RealityShortID = tls.Reality.ShortID
+		}
+	}
+
+	if transport := outbound.Transport; transport != nil {
+		var hosts []string
+		if err := json.Unmarshal(transport.Host, &hosts); err != nil {
+			var host string
+			if json.Unmarshal(transport.Host, &host) == nil && host != "" {
+				hosts = []string{host}
+			}
+		}
+
+		switch transport.Type {
+		case "ws", "httpupgrade":
+			node.Network = transport.Type
+			node.WsPath = transport.Path
+			node.WsHeaders = transport.Headers
+			if len(hosts) > 0 {
+				if node.WsHeaders == nil {
+					node.WsHeaders = make(map[string]string)
+				}
+				node.WsHeaders["Host"] = hosts[0]
+			}
+		case "grpc":
+			node.Network = "grpc"
+			node.GrpcServiceName = transport.ServiceName
+		case "http":
+			node.Network = "h2"
+			node.H2Path = transport.Path
+			node.H2Host = hosts
+		}
+	}
+
+	return node
+}
+
+// parseSingBoxWireGuard 兼容旧版 outbound 与 1.11 的 endpoint 写法
+func parseSingBoxWireGuard(node *model.Node, outbound *SingBoxOutbound) {
+	node.PrivateKey = outbound.PrivateKey
+	node.PublicKey = outbound.PeerPublicKey
+	node.PreSharedKey = outbound.PreSharedKey
+	node.Reserved = outbound.Reserved
+	node.MTU = outbound.MTU
+	node.UDP = true
+
+	if len(outbound.Peers) > 0 {
+		peer := outbound.Peers[0]
+		node.Server = peer.Address
+		node.Port = peer.Port
+		node.PublicKey = peer.PublicKey
+		node.PreSharedKey = peer.PreSharedKey
+		node.AllowedIPs = peer.AllowedIPs
+		node.Reserved = peer.Reserved
+	}
+
+	for _, address := range append(outbound.LocalAddress, outbound.Address...) {
+		ip, _, _ := strings.Cut(address, "/")
+		if strings.Contains(ip, ":") {
+			node.IPv6 = ip
+		} else {
+			node.IP = ip
+		}
+	}
+}
+
+// parsePluginOpts 解析 obfs=http;obfs-host=example.com 形式的插件参数
+func parsePluginOpts(opts string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(opts, ";") {
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, "=")
+		result[key] = value
+	}
+	return result
+}
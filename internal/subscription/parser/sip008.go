@@ -0,0 +1,107 @@
+// internal/subscription/parser/sip008.go
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"strings"
+)
+
+// SIP008Config SIP008 在线配置
+type SIP008Config struct {
+	Version        int             `json:"version"`
+	Servers        []*SIP008Server `json:"servers"`
+	BytesUsed      *uint64         `json:"bytes_used,omitempty"`      // 已用流量(字节)
+	BytesRemaining *uint64         `json:"bytes_remaining,omitempty"` // 剩余流量(字节)
+}
+
+// SIP008Server SIP008 中的单个服务器
+type SIP008Server struct {
+	ID         string `json:"id,omitempty"`
+	Remarks    string `json:"remarks"`
+	Server     string `json:"server"`
+	ServerPort int    `json:"server_port"`
+	Password   string `json:"password"`
+	Method     string `json:"method"`
+	Plugin     string `json:"plugin,omitempty"`
+	PluginOpts string `json:"plugin_opts,omitempty"`
+}
+
+// parseSIP008 解析 {"version":1,"servers":[...]}
+func parseSIP008(content string) ([]*model.Node, error) {
+	_, nodes, err := ParseSIP008(content)
+	return nodes, err
+}
+
+// ParseSIP008 解析 SIP008 配置，同时返回 bytes_used/bytes_remaining 等元数据
+func ParseSIP008(content string) (*SIP008Config, []*model.Node, error) {
+	var config SIP008Config
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal sip008 config: %v", err)
+	}
+
+	nodes := make([]*model.Node, 0, len(config.Servers))
+	for _, server := range config.Servers {
+		node := &model.Node{
+			Type:     model.TypeSS,
+			Name:     server.Remarks,
+			Server:   server.Server,
+			Port:     server.ServerPort,
+			Password: server.Password,
+			Cipher:   server.Method,
+			UDP:      true,
+			Settings: make(map[string]string),
+		}
+		if node.Name == "" {
+			node.Name = fmt.Sprintf("%s:%d", server.Server, server.ServerPort)
+		}
+		if server.Plugin != "" {
+			node.Plugin, node.PluginOpts = parseSSPlugin(server.Plugin + ";" + server.PluginOpts)
+		}
+		nodes = append(nodes, node)
+	}
+
+	return &config, nodes, nil
+}
+
+// EncodeSIP008 生成 SIP008 配置，SIP008 只能描述 SS 节点，其余节点原样返回
+func EncodeSIP008(nodes []*model.Node) ([]byte, []*model.Node, error) {
+	config := SIP008Config{
+		Version: 1,
+		Servers: make([]*SIP008Server, 0, len(nodes)),
+	}
+	skipped := make([]*model.Node, 0)
+	for _, node := range nodes {
+		server, err := NewSIP008Server(node)
+		if err != nil {
+			skipped = append(skipped, node)
+			continue
+		}
+		config.Servers = append(config.Servers, server)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal sip008 config: %v", err)
+	}
+	return data, skipped, nil
+}
+
+// NewSIP008Server 将 SS 节点转换为 SIP008 服务器
+func NewSIP008Server(node *model.Node) (*SIP008Server, error) {
+	if node.Type != model.TypeSS {
+		return nil, fmt.Errorf("node type %s is not supported by sip008", node.Type)
+	}
+	server := &SIP008Server{
+		Remarks:    node.Name,
+		Server:     node.Server,
+		ServerPort: node.Port,
+		Password:   node.Password,
+		Method:     node.Cipher,
+	}
+	if node.Plugin != "" {
+		server.Plugin, server.PluginOpts, _ = strings.Cut(encodeSSPlugin(node.Plugin, node.PluginOpts), ";")
+	}
+	return server, nil
+}
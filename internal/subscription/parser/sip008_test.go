@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestSIP008(t *testing.T) {
+	content := `{
+  "version": 1,
+  "servers": [
+    {
+      "id": "27b8a625-4f4b-4428-9f0f-8a2317db7c79",
+      "remarks": "香港 01",
+      "server": "hk.example.com",
+      "server_port": 8388,
+      "password": "pass",
+      "method": "aes-256-gcm",
+      "plugin": "obfs-local",
+      "plugin_opts": "obfs=http;obfs-host=bing.com"
+    },
+    {
+      "remarks": "",
+      "server": "jp.example.com",
+      "server_port": 8389,
+      "password": "pass",
+      "method": "chacha20-ietf-poly1305"
+    }
+  ],
+  "bytes_used": 274877906944,
+  "bytes_remaining": 824633720832
+}`
+
+	config, nodes, err := ParseSIP008(content)
+	if err != nil {
+		t.Fatalf("ParseSIP008() error = %v", err)
+	}
+	if config.BytesUsed == nil || *config.BytesUsed != 274877906944 || config.BytesRemaining == nil || *config.BytesRemaining != 824633720832 {
+		t.Errorf("bytes metadata = %v/%v", config.BytesUsed, config.BytesRemaining)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2", len(nodes))
+	}
+	assertEqual(t, "plugin", nodes[0].Plugin, "obfs")
+	assertEqual(t, "plugin opts", nodes[0].PluginOpts, map[string]string{"mode": "http", "host": "bing.com"})
+	assertEqual(t, "default name", nodes[1].Name, "jp.example.com:8389")
+
+	trojan := &model.Node{Type: model.TypeTrojan, Name: "trojan", Server: "t.example.com", Port: 443, Password: "pass"}
+	data, skipped, err := EncodeSIP008(append(nodes, trojan))
+	if err != nil {
+		t.Fatalf("EncodeSIP008() error = %v", err)
+	}
+	assertEqual(t, "skipped", skipped, []*model.Node{trojan})
+
+	_, reparsed, err := ParseSIP008(string(data))
+	if err != nil {
+		t.Fatalf("ParseSIP008() error = %v", err)
+	}
+	if !reflect.DeepEqual(reparsed, nodes) {
+		t.Errorf("round trip mismatch:\n%s", data)
+	}
+}
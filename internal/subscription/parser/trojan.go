@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"errors"
 	"goconverter/internal/subscription/model"
 	"net/url"
 	"strings"
@@ -17,40 +18,53 @@ func (p *TrojanParser) Match(link string) bool {
 	return strings.HasPrefix(link, "trojan://")
 }
 
+// Parse 解析 trojan://password@host:port?sni=...&type=ws&path=...#name
 func (p *TrojanParser) Parse(link string) (*model.Node, error) {
 	u, err := url.Parse(link)
 	if err != nil {
 		return nil, err
 	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("missing password in trojan link")
+	}
 
-	password := u.User.Username()
-	host := u.Host
-	name, _ := url.QueryUnescape(strings.TrimPrefix(u.Fragment, "#"))
-
-	// 解析查询参数
 	query := u.Query()
-	settings := make(map[string]string)
-	for k, v := range query {
-		if len(v) > 0 {
-			settings[k] = v[0]
-		}
+	node := &model.Node{
+		Type:              model.TypeTrojan,
+		Name:              u.Fragment,
+		Server:            u.Hostname(),
+		Port:              parseInt(u.Port()),
+		Password:          u.User.Username(),
+		TLS:               true,
+		SNI:               defaultIfEmpty(query.Get("sni"), query.Get("peer")),
+		ClientFingerprint: query.Get("fp"),
+		AllowInsecure:     parseBool(query.Get("allowInsecure")),
+		UDP:               true,
+		Settings:          make(map[string]string),
 	}
-
-	// 分离端口
-	hostParts := strings.Split(host, ":")
-	server := hostParts[0]
-	port := 443
-	if len(hostParts) > 1 {
-		port = parseInt(hostParts[1])
+	if node.Port == 0 {
+		node.Port = 443
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+	if alpn := query.Get("alpn"); alpn != "" {
+		node.ALPN = strings.Split(alpn, ",")
 	}
 
-	return &model.Node{
-		Type:     "trojan",
-		Name:     name,
-		Server:   server,
-		Port:     port,
-		Protocol: "trojan",
-		Password: password,
-		Settings: settings,
-	}, nil
+	parseTransport(node, query)
+
+	return node, nil
+}
+
+// Encode 生成 trojan:// 分享链接
+func (p *TrojanParser) Encode(node *model.Node) (string, error) {
+	query := url.Values{}
+	setParam(query, "sni", node.SNI)
+	setParam(query, "fp", node.ClientFingerprint)
+	setParam(query, "alpn", strings.Join(node.ALPN, ","))
+	setBoolParam(query, "allowInsecure", node.AllowInsecure)
+	encodeTransport(node, query)
+
+	return buildLink("trojan", url.User(node.Password), node, query), nil
 }
@@ -0,0 +1,132 @@
+// internal/subscription/parser/userinfo.go
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserInfoHeader 机场返回流量与到期时间的响应头
+const UserInfoHeader = "Subscription-Userinfo"
+
+// UserInfo 订阅的流量与到期信息，单位为字节，Expire 为 Unix 时间戳，0 表示未知
+type UserInfo struct {
+	Upload   uint64
+	Download uint64
+	Total    uint64
+	Expire   int64
+}
+
+// ParseUserInfo 解析 upload=..; download=..; total=..; expire=..，没有任何字段时返回 nil
+func ParseUserInfo(value string) *UserInfo {
+	info := &UserInfo{}
+	found := false
+	for _, item := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		// 部分机场会返回小数或科学计数法
+		number, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || number < 0 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "upload":
+			info.Upload = uint64(number)
+		case "download":
+			info.Download = uint64(number)
+		case "total":
+			info.Total = uint64(number)
+		case "expire":
+			info.Expire = int64(number)
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return info
+}
+
+// UserInfoFromSIP008 读取 SIP008 中的 bytes_used/bytes_remaining
+func UserInfoFromSIP008(content string) *UserInfo {
+	config, _, err := ParseSIP008(content)
+	if err != nil || (config.BytesUsed == nil && config.BytesRemaining == nil) {
+		return nil
+	}
+	info := &UserInfo{}
+	if config.BytesUsed != nil {
+		info.Download = *config.BytesUsed
+	}
+	if config.BytesRemaining != nil {
+		info.Total = info.Download + *config.BytesRemaining
+	}
+	return info
+}
+
+// Used 已用流量
+func (u *UserInfo) Used() uint64 {
+	return u.Upload + u.Download
+}
+
+// Remaining 剩余流量，总量未知时返回 0
+func (u *UserInfo) Remaining() uint64 {
+	if u.Total <= u.Used() {
+		return 0
+	}
+	return u.Total - u.Used()
+}
+
+// Add 合并另一个订阅的信息：流量相加，到期时间取最早的
+func (u *UserInfo) Add(other *UserInfo) {
+	if other == nil {
+		return
+	}
+	u.Upload += other.Upload
+	u.Download += other.Download
+	u.Total += other.Total
+	if other.Expire > 0 && (u.Expire == 0 || other.Expire < u.Expire) {
+		u.Expire = other.Expire
+	}
+}
+
+// String 生成 subscription-userinfo 响应头
+func (u *UserInfo) String() string {
+	value := fmt.Sprintf("upload=%d; download=%d; total=%d", u.Upload, u.Download, u.Total)
+	if u.Expire > 0 {
+		value += fmt.Sprintf("; expire=%d", u.Expire)
+	}
+	return value
+}
+
+// Summary 生成展示用的摘要，如 "剩余 120GB | 2026-12-01"
+func (u *UserInfo) Summary() string {
+	var traffic string
+	if u.Total > 0 {
+		traffic = "剩余 " + FormatBytes(u.Remaining())
+	} else {
+		traffic = "已用 " + FormatBytes(u.Used())
+	}
+	if u.Expire <= 0 {
+		return traffic
+	}
+	return traffic + " | " + time.Unix(u.Expire, 0).Format("2006-01-02")
+}
+
+// FormatBytes 以 1024 为进制格式化流量，最多保留两位小数
+func FormatBytes(size uint64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	return strings.TrimRight(strings.TrimRight(formatted, "0"), ".") + units[unit]
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseUserInfo(t *testing.T) {
+	expire := time.Date(2026, 12, 1, 12, 0, 0, 0, time.Local).Unix()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    *UserInfo
+		summary string
+	}{
+		{
+			name:    "full",
+			value:   "upload=1073741824; download=9663676416; total=139586437120",
+			want:    &UserInfo{Upload: 1 << 30, Download: 9 << 30, Total: 130 << 30},
+			summary: "剩余 120GB",
+		},
+		{
+			name:    "float and expire",
+			value:   "upload=0;download=1.5e9;total=0;expire=" + strconv.FormatInt(expire, 10),
+			want:    &UserInfo{Download: 1500000000, Expire: expire},
+			summary: "已用 1.4GB | 2026-12-01",
+		},
+		{
+			name:  "empty",
+			value: "foo=bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseUserInfo(tt.value)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("ParseUserInfo() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Fatalf("ParseUserInfo() = %+v, want %+v", got, tt.want)
+			}
+			if summary := got.Summary(); summary != tt.summary {
+				t.Errorf("Summary() = %q, want %q", summary, tt.summary)
+			}
+		})
+	}
+}
+
+func TestUserInfoAdd(t *testing.T) {
+	info := &UserInfo{Upload: 1, Download: 2, Total: 10, Expire: 200}
+	info.Add(&UserInfo{Upload: 1, Download: 1, Total: 5, Expire: 100})
+	info.Add(&UserInfo{Total: 5})
+	info.Add(nil)
+	if want := (UserInfo{Upload: 2, Download: 3, Total: 20, Expire: 100}); *info != want {
+		t.Errorf("Add() = %+v, want %+v", *info, want)
+	}
+	if want := "upload=2; download=3; total=20; expire=100"; info.String() != want {
+		t.Errorf("String() = %q, want %q", info.String(), want)
+	}
+
+	sip008 := UserInfoFromSIP008(`{"version":1,"servers":[],"bytes_used":100,"bytes_remaining":300}`)
+	if sip008 == nil || *sip008 != (UserInfo{Download: 100, Total: 400}) {
+		t.Errorf("UserInfoFromSIP008() = %+v", sip008)
+	}
+}
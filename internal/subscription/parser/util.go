@@ -1,9 +1,46 @@
 // internal/subscription/parser/utils.go
 package parser
 
-import "strconv"
+import (
+	"goconverter/internal/subscription/model"
+	"net"
+	"net/url"
+	"strconv"
+)
 
 func parseInt(s string) int {
 	n, _ := strconv.Atoi(s)
 	return n
 }
+
+// parseBool 解析分享链接中的布尔参数，兼容 1/0 与 true/false
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+// buildLink 拼接 scheme://userinfo@host:port?query#name 形式的分享链接
+func buildLink(scheme string, user *url.Userinfo, node *model.Node, query url.Values) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     user,
+		Host:     net.JoinHostPort(node.Server, strconv.Itoa(node.Port)),
+		RawQuery: query.Encode(),
+		Fragment: node.Name,
+	}
+	return u.String()
+}
+
+// setParam 只在值不为空时设置查询参数
+func setParam(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// setBoolParam 只在值为 true 时设置查询参数为 1
+func setBoolParam(query url.Values, key string, value bool) {
+	if value {
+		query.Set(key, "1")
+	}
+}
@@ -0,0 +1,137 @@
+// internal/subscription/parser/vless.go
+package parser
+
+import (
+	"errors"
+	"goconverter/internal/subscription/model"
+	"net/url"
+	"strings"
+)
+
+type VlessParser struct{}
+
+func NewVlessParser() *VlessParser {
+	return &VlessParser{}
+}
+
+func (p *VlessParser) Match(link string) bool {
+	return strings.HasPrefix(link, "vless://")
+}
+
+// Parse 解析 vless://uuid@host:port?security=reality&pbk=...&type=ws#name
+func (p *VlessParser) Parse(link string) (*model.Node, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("missing uuid in vless link")
+	}
+
+	query := u.Query()
+	node := &model.Node{
+		Type:              model.TypeVless,
+		Name:              u.Fragment,
+		Server:            u.Hostname(),
+		Port:              parseInt(u.Port()),
+		UUID:              u.User.Username(),
+		Cipher:            query.Get("encryption"),
+		Flow:              query.Get("flow"),
+		SNI:               query.Get("sni"),
+		ClientFingerprint: query.Get("fp"),
+		AllowInsecure:     parseBool(query.Get("allowInsecure")),
+		UDP:               true,
+		Settings:          make(map[string]string),
+	}
+	if node.Port == 0 {
+		node.Port = 443
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+
+	switch query.Get("security") {
+	case "tls":
+		node.TLS = true
+	case "reality":
+		node.TLS = true
+		node.RealityPublicKey = query.Get("pbk")
+		node.RealityShortID = query.Get("sid")
+		node.RealitySpiderX = query.Get("spx")
+	}
+	if alpn := query.Get("alpn"); alpn != "" {
+		node.ALPN = strings.Split(alpn, ",")
+	}
+
+	parseTransport(node, query)
+
+	return node, nil
+}
+
+// Encode 生成 vless:// 分享链接
+func (p *VlessParser) Encode(node *model.Node) (string, error) {
+	query := url.Values{}
+	setParam(query, "encryption", node.Cipher)
+	setParam(query, "flow", node.Flow)
+	if node.RealityPublicKey != "" {
+		query.Set("security", "reality")
+		query.Set("pbk", node.RealityPublicKey)
+		setParam(query, "sid", node.RealityShortID)
+		setParam(query, "spx", node.RealitySpiderX)
+	} else if node.TLS {
+		query.Set("security", "tls")
+	}
+	setParam(query, "sni", node.SNI)
+	setParam(query, "fp", node.ClientFingerprint)
+	setParam(query, "alpn", strings.Join(node.ALPN, ","))
+	setBoolParam(query, "allowInsecure", node.AllowInsecure)
+	encodeTransport(node, query)
+
+	return buildLink("vless", url.User(node.UUID), node, query), nil
+}
+
+// parseTransport 解析分享链接中通用的 type/host/path/serviceName 传输层参数
+func parseTransport(node *model.Node, query url.Values) {
+	network := query.Get("type")
+	host := query.Get("host")
+	path := query.Get("path")
+
+	switch network {
+	case "ws", "httpupgrade":
+		node.Network = network
+		node.WsPath = path
+		if host != "" {
+			node.WsHeaders = map[string]string{"Host": host}
+		}
+	case "grpc":
+		node.Network = network
+		node.GrpcServiceName = query.Get("serviceName")
+	case "h2", "http":
+		node.Network = "h2"
+		node.H2Path = path
+		if host != "" {
+			node.H2Host = strings.Split(host, ",")
+		}
+	default:
+		node.Network = "tcp"
+	}
+}
+
+// encodeTransport 与 parseTransport 相反，写入 type/host/path/serviceName
+func encodeTransport(node *model.Node, query url.Values) {
+	switch node.Network {
+	case "ws", "httpupgrade":
+		query.Set("type", node.Network)
+		setParam(query, "host", node.WsHeaders["Host"])
+		setParam(query, "path", node.WsPath)
+	case "grpc":
+		query.Set("type", "grpc")
+		setParam(query, "serviceName", node.GrpcServiceName)
+	case "h2":
+		query.Set("type", "h2")
+		setParam(query, "host", strings.Join(node.H2Host, ","))
+		setParam(query, "path", node.H2Path)
+	default:
+		query.Set("type", "tcp")
+	}
+}
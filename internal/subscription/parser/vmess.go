@@ -4,8 +4,11 @@ package parser
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"strconv"
 	"strings"
 )
 
@@ -15,49 +18,145 @@ func NewVmessParser() *VmessParser {
 	return &VmessParser{}
 }
 
+// VmessLink v2rayN 分享链接中的 JSON
 type VmessLink struct {
-	Version string `json:"v"`
-	Name    string `json:"ps"`
-	Address string `json:"add"`
-	Port    int    `json:"port"`
-	UUID    string `json:"id"`
-	AlterId int    `json:"aid"`
-	Network string `json:"net"`
-	Type    string `json:"type"`
-	TLS     string `json:"tls"`
+	Version  string    `json:"v"`
+	Name     string    `json:"ps"`
+	Address  string    `json:"add"`
+	Port     stringInt `json:"port"`
+	UUID     string    `json:"id"`
+	AlterId  stringInt `json:"aid"`
+	Security string    `json:"scy,omitempty"`
+	Network  string    `json:"net"`
+	Type     string    `json:"type"`
+	Host     string    `json:"host,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	TLS      string    `json:"tls"`
+	SNI      string    `json:"sni,omitempty"`
+	ALPN     string    `json:"alpn,omitempty"`
+	Fp       string    `json:"fp,omitempty"`
+}
+
+// stringInt 兼容 "443" 与 443 两种写法，输出时使用字符串
+type stringInt int
+
+func (i *stringInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*i = stringInt(n)
+	return nil
+}
+
+func (i stringInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.Itoa(int(i)))
 }
 
 func (p *VmessParser) Match(link string) bool {
 	return strings.HasPrefix(link, "vmess://")
 }
 
+// Parse 解析 v2rayN 格式的 vmess://base64(json)
 func (p *VmessParser) Parse(link string) (*model.Node, error) {
 	link = strings.TrimPrefix(link, "vmess://")
 
-	decoded, err := base64.RawURLEncoding.DecodeString(link)
+	decoded, err := utils.Base64Decode(link)
 	if err != nil {
 		return nil, err
 	}
 
 	var vmessLink VmessLink
-	if err := json.Unmarshal(decoded, &vmessLink); err != nil {
+	if err := json.Unmarshal([]byte(decoded), &vmessLink); err != nil {
 		return nil, err
 	}
+	if vmessLink.UUID == "" {
+		return nil, errors.New("missing uuid in vmess link")
+	}
+
+	node := &model.Node{
+		Type:              model.TypeVmess,
+		Name:              vmessLink.Name,
+		Server:            vmessLink.Address,
+		Port:              int(vmessLink.Port),
+		UUID:              vmessLink.UUID,
+		AlterID:           int(vmessLink.AlterId),
+		Cipher:            defaultIfEmpty(vmessLink.Security, "auto"),
+		TLS:               vmessLink.TLS == "tls",
+		SNI:               vmessLink.SNI,
+		ClientFingerprint: vmessLink.Fp,
+		UDP:               true,
+		Settings:          make(map[string]string),
+	}
+	if node.Name == "" {
+		node.Name = fmt.Sprintf("%s:%d", node.Server, node.Port)
+	}
+	if vmessLink.ALPN != "" {
+		node.ALPN = strings.Split(vmessLink.ALPN, ",")
+	}
 
-	settings := map[string]string{
-		"uuid":    vmessLink.UUID,
-		"alterId": fmt.Sprintf("%d", vmessLink.AlterId),
-		"network": vmessLink.Network,
-		"type":    vmessLink.Type,
-		"tls":     vmessLink.TLS,
+	switch vmessLink.Network {
+	case "ws", "httpupgrade":
+		node.Network = vmessLink.Network
+		node.WsPath = vmessLink.Path
+		if vmessLink.Host != "" {
+			node.WsHeaders = map[string]string{"Host": vmessLink.Host}
+		}
+	case "grpc":
+		node.Network = "grpc"
+		node.GrpcServiceName = vmessLink.Path
+	case "h2", "http":
+		node.Network = "h2"
+		node.H2Path = vmessLink.Path
+		if vmessLink.Host != "" {
+			node.H2Host = strings.Split(vmessLink.Host, ",")
+		}
+	default:
+		node.Network = "tcp"
 	}
 
-	return &model.Node{
-		Type:     "vmess",
-		Name:     vmessLink.Name,
-		Server:   vmessLink.Address,
-		Port:     vmessLink.Port,
-		Protocol: "vmess",
-		Settings: settings,
-	}, nil
+	return node, nil
+}
+
+// Encode 生成 v2rayN 格式的分享链接
+func (p *VmessParser) Encode(node *model.Node) (string, error) {
+	vmessLink := VmessLink{
+		Version:  "2",
+		Name:     node.Name,
+		Address:  node.Server,
+		Port:     stringInt(node.Port),
+		UUID:     node.UUID,
+		AlterId:  stringInt(node.AlterID),
+		Security: node.Cipher,
+		Network:  defaultIfEmpty(node.Network, "tcp"),
+		Type:     "none",
+		SNI:      node.SNI,
+		ALPN:     strings.Join(node.ALPN, ","),
+		Fp:       node.ClientFingerprint,
+	}
+	if node.TLS {
+		vmessLink.TLS = "tls"
+	}
+
+	switch node.Network {
+	case "ws", "httpupgrade":
+		vmessLink.Path = node.WsPath
+		vmessLink.Host = node.WsHeaders["Host"]
+	case "grpc":
+		vmessLink.Path = node.GrpcServiceName
+	case "h2":
+		vmessLink.Path = node.H2Path
+		vmessLink.Host = strings.Join(node.H2Host, ",")
+	}
+
+	data, err := json.Marshal(vmessLink)
+	if err != nil {
+		return "", err
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(data), nil
 }
@@ -0,0 +1,166 @@
+// internal/subscription/parser/wireguard.go
+package parser
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type WireGuardParser struct{}
+
+func NewWireGuardParser() *WireGuardParser {
+	return &WireGuardParser{}
+}
+
+func (p *WireGuardParser) Match(link string) bool {
+	return strings.HasPrefix(link, "wireguard://") || strings.HasPrefix(link, "wg://")
+}
+
+// Parse 解析 wireguard://privatekey@host:port?publickey=...&address=10.0.0.2/32,fd00::2/128&reserved=1,2,3#name
+func (p *WireGuardParser) Parse(link string) (*model.Node, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	node := &model.Node{
+		Type:         model.TypeWireGuard,
+		Name:         u.Fragment,
+		Server:       u.Hostname(),
+		Port:         parseInt(u.Port()),
+		PrivateKey:   defaultIfEmpty(userinfoPassword(u), rawQueryValue(u.RawQuery, "privatekey")),
+		PublicKey:    defaultIfEmpty(rawQueryValue(u.RawQuery, "publickey"), rawQueryValue(u.RawQuery, "peer")),
+		PreSharedKey: rawQueryValue(u.RawQuery, "presharedkey"),
+		MTU:          parseInt(query.Get("mtu")),
+		UDP:          true,
+		Settings:     make(map[string]string),
+	}
+	if node.PrivateKey == "" || node.PublicKey == "" {
+		return nil, errors.New("missing private key or public key in wireguard link")
+	}
+	for _, key := range []string{node.PrivateKey, node.PublicKey, node.PreSharedKey} {
+		if key != "" && !validWireGuardKey(key) {
+			return nil, fmt.Errorf("invalid wireguard key %q", key)
+		}
+	}
+	if node.Port == 0 {
+		node.Port = 51820
+	}
+	if node.Name == "" {
+		node.Name = u.Host
+	}
+
+	for _, address := range splitList(defaultIfEmpty(query.Get("address"), query.Get("ip"))) {
+		ip, _, _ := strings.Cut(address, "/")
+		if strings.Contains(ip, ":") {
+			node.IPv6 = ip
+		} else {
+			node.IP = ip
+		}
+	}
+	node.Reserved = parseReserved(query.Get("reserved"))
+	node.AllowedIPs = splitList(query.Get("allowedips"))
+
+	return node, nil
+}
+
+// Encode 生成 wireguard:// 分享链接
+func (p *WireGuardParser) Encode(node *model.Node) (string, error) {
+	query := url.Values{}
+	query.Set("publickey", node.PublicKey)
+	setParam(query, "presharedkey", node.PreSharedKey)
+	addresses := make([]string, 0, 2)
+	if node.IP != "" {
+		addresses = append(addresses, node.IP+"/32")
+	}
+	if node.IPv6 != "" {
+		addresses = append(addresses, node.IPv6+"/128")
+	}
+	setParam(query, "address", strings.Join(addresses, ","))
+	if len(node.Reserved) > 0 {
+		reserved := make([]string, 0, len(node.Reserved))
+		for _, b := range node.Reserved {
+			reserved = append(reserved, strconv.Itoa(b))
+		}
+		query.Set("reserved", strings.Join(reserved, ","))
+	}
+	if node.MTU > 0 {
+		query.Set("mtu", strconv.Itoa(node.MTU))
+	}
+	setParam(query, "allowedips", strings.Join(node.AllowedIPs, ","))
+
+	return buildLink("wireguard", url.User(node.PrivateKey), node, query), nil
+}
+
+// rawQueryValue 读取查询参数并保留字面量 +，分享链接中的 base64 密钥通常没有百分号编码
+func rawQueryValue(rawQuery, key string) string {
+	for _, pair := range strings.Split(rawQuery, "&") {
+		name, value, _ := strings.Cut(pair, "=")
+		if name != key {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			return unescaped
+		}
+		return value
+	}
+	return ""
+}
+
+// validWireGuardKey 判断密钥是否为 32 字节的 base64
+func validWireGuardKey(key string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		decoded, err = base64.URLEncoding.DecodeString(key)
+	}
+	return err == nil && len(decoded) == 32
+}
+
+// parseReserved 解析 reserved，兼容 1,2,3 与 base64 两种写法
+func parseReserved(value string) []int {
+	if value == "" {
+		return nil
+	}
+	parts := splitList(value)
+	reserved := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			reserved = nil
+			break
+		}
+		reserved = append(reserved, n)
+	}
+	if reserved != nil {
+		return reserved
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	for _, b := range decoded {
+		reserved = append(reserved, int(b))
+	}
+	return reserved
+}
+
+// splitList 按逗号拆分并去掉空白项
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
@@ -0,0 +1,85 @@
+// internal/subscription/processor/emoji.go
+package processor
+
+import (
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"strings"
+)
+
+// EmojiRule 名称匹配时添加的旗帜
+type EmojiRule struct {
+	Matcher *utils.Matcher
+	Emoji   string
+}
+
+// defaultEmojiRules 由内置地区表生成的旗帜规则
+var defaultEmojiRules = regionEmojiRules()
+
+func regionEmojiRules() []EmojiRule {
+	rules := make([]EmojiRule, 0, len(Regions))
+	for _, region := range Regions {
+		rules = append(rules, EmojiRule{
+			Matcher: utils.MustCompileMatcher(region.Pattern()),
+			Emoji:   region.Flag(),
+		})
+	}
+	return rules
+}
+
+// ParseEmojiRule 解析 subconverter 的 emoji 规则：regex,emoji
+func ParseEmojiRule(value string) (EmojiRule, error) {
+	index := strings.LastIndex(value, ",")
+	if index < 0 {
+		return EmojiRule{}, fmt.Errorf("invalid emoji rule %q", value)
+	}
+	pattern, emoji := value[:index], strings.TrimSpace(value[index+1:])
+	matcher, err := utils.CompileMatcher(pattern)
+	if err != nil {
+		return EmojiRule{}, fmt.Errorf("invalid emoji pattern %q: %v", pattern, err)
+	}
+	return EmojiRule{Matcher: matcher, Emoji: emoji}, nil
+}
+
+// ParseEmojiRules 解析多条 emoji 规则
+func ParseEmojiRules(values []string) ([]EmojiRule, error) {
+	rules := make([]EmojiRule, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		rule, err := ParseEmojiRule(value)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// AddEmoji 为节点名称添加第一条匹配规则的旗帜，rules 为空时使用内置地区表
+func AddEmoji(nodes []*model.Node, rules []EmojiRule) {
+	if len(rules) == 0 {
+		rules = defaultEmojiRules
+	}
+	for _, node := range nodes {
+		for _, rule := range rules {
+			if rule.Matcher.MatchString(node.Name) {
+				if !strings.HasPrefix(node.Name, rule.Emoji) {
+					node.Name = rule.Emoji + " " + node.Name
+				}
+				break
+			}
+		}
+	}
+	UniqueNames(nodes)
+}
+
+// RemoveOldEmoji 去掉节点名称中已有的 emoji
+func RemoveOldEmoji(nodes []*model.Node) {
+	for _, node := range nodes {
+		node.Name = RemoveEmoji(node.Name)
+	}
+	UniqueNames(nodes)
+}
@@ -0,0 +1,180 @@
+// internal/subscription/processor/filter.go
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"log"
+	"net"
+	"strings"
+)
+
+// FilterOptions 节点过滤条件，字段为空表示不限制
+type FilterOptions struct {
+	IncludeRemarks   []string // 备注需匹配其中任一正则
+	ExcludeRemarks   []string // 备注匹配其中任一正则即排除
+	Protocols        []string // 允许的协议，如 ss、vmess
+	ExcludeProtocols []string // 排除的协议
+	Ports            string   // 端口范围，如 443,8000-9000,!8080，! 开头的条目在其余条目匹配后排除
+	BlockServers     []string // 屏蔽的服务器，CIDR、IP 或域名后缀
+}
+
+// Filter 编译后的节点过滤器
+type Filter struct {
+	include          []*utils.Matcher
+	exclude          []*utils.Matcher
+	protocols        map[string]bool
+	excludeProtocols map[string]bool
+	ports            string // 允许的端口范围，为空时不限制
+	excludePorts     string // 排除的端口范围
+	blockedNets      []*net.IPNet
+	blockedDomains   []string
+}
+
+// NewFilter 编译过滤条件
+func NewFilter(options FilterOptions) (*Filter, error) {
+	include, err := compileMatchers(options.IncludeRemarks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %v", err)
+	}
+	exclude, err := compileMatchers(options.ExcludeRemarks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %v", err)
+	}
+
+	filter := &Filter{
+		include:          include,
+		exclude:          exclude,
+		protocols:        protocolSet(options.Protocols),
+		excludeProtocols: protocolSet(options.ExcludeProtocols),
+	}
+	for _, item := range strings.Split(options.Ports, ",") {
+		item = strings.TrimSpace(item)
+		if after, found := strings.CutPrefix(item, "!"); found {
+			filter.excludePorts += after + ","
+		} else if item != "" {
+			filter.ports += item + ","
+		}
+	}
+	for _, server := range options.BlockServers {
+		server = strings.ToLower(strings.TrimSpace(server))
+		if server == "" {
+			continue
+		}
+		if strings.Contains(server, "/") {
+			_, ipNet, err := net.ParseCIDR(server)
+			if err != nil {
+				return nil, fmt.Errorf("invalid server cidr %s: %v", server, err)
+			}
+			filter.blockedNets = append(filter.blockedNets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(server); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			filter.blockedNets = append(filter.blockedNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		filter.blockedDomains = append(filter.blockedDomains, strings.TrimLeft(server, "+."))
+	}
+	return filter, nil
+}
+
+// Apply 返回满足过滤条件的节点
+func (f *Filter) Apply(nodes []*model.Node) []*model.Node {
+	result := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if err := f.check(node); err != nil {
+			log.Printf("过滤节点 %s: %v", node.Name, err)
+			continue
+		}
+		result = append(result, node)
+	}
+	return result
+}
+
+// check 检查单个节点，不满足条件时返回原因
+func (f *Filter) check(node *model.Node) error {
+	if len(f.include) > 0 && !matchAny(f.include, node.Name) {
+		return errors.New("remark does not match include patterns")
+	}
+	if matchAny(f.exclude, node.Name) {
+		return errors.New("remark matches exclude patterns")
+	}
+	protocol := string(node.Type)
+	if len(f.protocols) > 0 && !f.protocols[protocol] {
+		return fmt.Errorf("protocol %s is not allowed", protocol)
+	}
+	if f.excludeProtocols[protocol] {
+		return fmt.Errorf("protocol %s is excluded", protocol)
+	}
+	if f.ports != "" && !utils.MatchRange(f.ports, node.Port) {
+		return fmt.Errorf("port %d is out of range %s", node.Port, strings.TrimSuffix(f.ports, ","))
+	}
+	if f.excludePorts != "" && utils.MatchRange(f.excludePorts, node.Port) {
+		return fmt.Errorf("port %d is excluded", node.Port)
+	}
+	if ip := net.ParseIP(node.Server); ip != nil {
+		for _, ipNet := range f.blockedNets {
+			if ipNet.Contains(ip) {
+				return fmt.Errorf("server %s is blocked by %s", node.Server, ipNet)
+			}
+		}
+	} else {
+		server := strings.ToLower(strings.TrimSuffix(node.Server, "."))
+		for _, domain := range f.blockedDomains {
+			if server == domain || strings.HasSuffix(server, "."+domain) {
+				return fmt.Errorf("server %s is blocked by %s", node.Server, domain)
+			}
+		}
+	}
+	return nil
+}
+
+// ForceUDP 统一设置节点的 UDP 开关
+func ForceUDP(nodes []*model.Node, udp bool) {
+	for _, node := range nodes {
+		node.UDP = udp
+	}
+}
+
+func compileMatchers(patterns []string) ([]*utils.Matcher, error) {
+	matchers := make([]*utils.Matcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		matcher, err := utils.CompileMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func matchAny(matchers []*utils.Matcher, name string) bool {
+	for _, matcher := range matchers {
+		if matcher.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// protocolSet 协议列表转为集合，忽略大小写与空白
+func protocolSet(protocols []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, protocol := range protocols {
+		for _, item := range strings.Split(protocol, ",") {
+			if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+				set[item] = true
+			}
+		}
+	}
+	return set
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	nodes := []*model.Node{
+		{Type: model.TypeTrojan, Name: "香港 01", Server: "hk.example.com", Port: 443},
+		{Type: model.TypeSS, Name: "日本 01", Server: "1.2.3.4", Port: 8388},
+		{Type: model.TypeVmess, Name: "美国 01", Server: "us.bad.net", Port: 443},
+		{Type: model.TypeSS, Name: "剩余流量：10GB", Server: "info.example.com", Port: 443},
+		{Type: model.TypeSS, Name: "新加坡 01", Server: "2001:db8::1", Port: 8080},
+		{Type: model.TypeSocks5, Name: "SSH 01", Server: "ssh.example.com", Port: 22},
+	}
+
+	tests := []struct {
+		name    string
+		options FilterOptions
+		want    []string
+	}{
+		{
+			name:    "no filter",
+			options: FilterOptions{},
+			want:    []string{"香港 01", "日本 01", "美国 01", "剩余流量：10GB", "新加坡 01", "SSH 01"},
+		},
+		{
+			name: "remarks",
+			options: FilterOptions{
+				IncludeRemarks: []string{"香港|日本", "流量"},
+				ExcludeRemarks: []string{"剩余流量|到期时间|官网"},
+			},
+			want: []string{"香港 01", "日本 01"},
+		},
+		{
+			name:    "protocols",
+			options: FilterOptions{Protocols: []string{"SS, trojan"}, ExcludeProtocols: []string{"trojan"}},
+			want:    []string{"日本 01", "剩余流量：10GB", "新加坡 01"},
+		},
+		{
+			name:    "ports",
+			options: FilterOptions{Ports: "443,8000-9000,!8080"},
+			want:    []string{"香港 01", "日本 01", "美国 01", "剩余流量：10GB"},
+		},
+		{
+			name:    "exclude ports only",
+			options: FilterOptions{Ports: "!8080,!20-30"},
+			want:    []string{"香港 01", "日本 01", "美国 01", "剩余流量：10GB"},
+		},
+		{
+			name:    "block servers",
+			options: FilterOptions{BlockServers: []string{"1.2.0.0/16", "bad.net", "2001:db8::1", ""}},
+			want:    []string{"香港 01", "剩余流量：10GB", "SSH 01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.options)
+			if err != nil {
+				t.Fatalf("NewFilter() error = %v", err)
+			}
+			var got []string
+			for _, node := range filter.Apply(nodes) {
+				got = append(got, node.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFilter(FilterOptions{BlockServers: []string{"1.2.3.4/40"}}); err == nil {
+		t.Error("NewFilter() should reject invalid cidr")
+	}
+}
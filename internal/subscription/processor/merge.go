@@ -0,0 +1,108 @@
+// internal/subscription/processor/merge.go
+package processor
+
+import (
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/subscription/parser"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// Source 单个订阅的解析结果
+type Source struct {
+	Name  string           // 订阅名称，写入节点的 Group 与 Tags
+	Nodes []*model.Node    // 订阅中的节点
+	Info  *parser.UserInfo // 流量与到期信息，可能为空
+}
+
+// ParseSource 解析订阅地址，兼容 subconverter 的 tag:名称,URL 写法，未指定名称时使用域名
+func ParseSource(value string) (name, rawURL string) {
+	if rest, found := strings.CutPrefix(value, "tag:"); found {
+		if name, rawURL, found = strings.Cut(rest, ","); found {
+			return strings.TrimSpace(name), strings.TrimSpace(rawURL)
+		}
+	}
+	rawURL = strings.TrimSpace(value)
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		return u.Hostname(), rawURL
+	}
+	return rawURL, rawURL
+}
+
+// Merge 按顺序合并多个订阅：标记来源、去除重复节点并处理重名
+func Merge(sources []Source) []*model.Node {
+	nodes := make([]*model.Node, 0)
+	seen := make(map[string]*model.Node)
+	for i, source := range sources {
+		for _, node := range source.Nodes {
+			key := nodeKey(node)
+			if kept, found := seen[key]; found {
+				// 重复节点只保留第一个，来源记录到已保留节点的标签中
+				log.Printf("跳过重复节点 %s: 与 %s 相同", node.Name, kept.Name)
+				addTag(kept, source.Name)
+				continue
+			}
+			seen[key] = node
+
+			node.GroupID = i
+			if node.Group == "" {
+				node.Group = source.Name
+			}
+			addTag(node, source.Name)
+			nodes = append(nodes, node)
+		}
+	}
+
+	UniqueNames(nodes)
+	return nodes
+}
+
+// UniqueNames 为重名节点依次添加 " 2"、" 3" 等后缀，第一个节点保持原名
+func UniqueNames(nodes []*model.Node) {
+	used := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		used[node.Name] = true
+	}
+
+	named := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if !named[node.Name] {
+			named[node.Name] = true
+			continue
+		}
+		name := node.Name
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s %d", node.Name, i)
+		}
+		used[name] = true
+		named[name] = true
+		node.Name = name
+	}
+}
+
+// nodeKey 节点去重依据：协议、地址、端口与认证信息
+func nodeKey(node *model.Node) string {
+	return strings.Join([]string{
+		string(node.Type),
+		strings.ToLower(node.Server),
+		fmt.Sprint(node.Port),
+		node.Username,
+		node.Password,
+		node.UUID,
+		node.PrivateKey,
+	}, "\x00")
+}
+
+func addTag(node *model.Node, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, existing := range node.Tags {
+		if existing == tag {
+			return
+		}
+	}
+	node.Tags = append(node.Tags, tag)
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	sources := []Source{
+		{Name: "A", Nodes: []*model.Node{
+			{Type: model.TypeTrojan, Name: "香港 01", Server: "hk.example.com", Port: 443, Password: "a"},
+			{Type: model.TypeTrojan, Name: "日本 01", Server: "jp.example.com", Port: 443, Password: "a"},
+		}},
+		{Name: "B", Nodes: []*model.Node{
+			// 与 A 的香港 01 相同，仅名称和大小写不同
+			{Type: model.TypeTrojan, Name: "HK", Server: "HK.example.com", Port: 443, Password: "a"},
+			// 认证信息不同，保留并加后缀
+			{Type: model.TypeTrojan, Name: "香港 01", Server: "hk.example.com", Port: 443, Password: "b"},
+			{Type: model.TypeSS, Name: "香港 01 2", Server: "hk2.example.com", Port: 8388, Password: "a"},
+		}},
+	}
+
+	nodes := Merge(sources)
+
+	var names, groups []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		groups = append(groups, node.Group)
+	}
+	if want := []string{"香港 01", "日本 01", "香港 01 3", "香港 01 2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if want := []string{"A", "A", "B", "B"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %v, want %v", groups, want)
+	}
+	if want := []string{"A", "B"}; !reflect.DeepEqual(nodes[0].Tags, want) {
+		t.Errorf("tags = %v, want %v", nodes[0].Tags, want)
+	}
+	if nodes[2].GroupID != 1 {
+		t.Errorf("group id = %d, want 1", nodes[2].GroupID)
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		value, name, url string
+	}{
+		{"https://sub.example.com/api?token=1", "sub.example.com", "https://sub.example.com/api?token=1"},
+		{"tag:机场A,https://sub.example.com/api", "机场A", "https://sub.example.com/api"},
+		{"nodes.txt", "nodes.txt", "nodes.txt"},
+	}
+	for _, tt := range tests {
+		name, rawURL := ParseSource(tt.value)
+		if name != tt.name || rawURL != tt.url {
+			t.Errorf("ParseSource(%q) = %q, %q, want %q, %q", tt.value, name, rawURL, tt.name, tt.url)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// internal/subscription/processor/region.go
+package processor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Region 地区识别表中的一项
+type Region struct {
+	Code  string   // ISO 3166-1 代码
+	Codes []string // 名称中出现的代码写法，需独立成词
+	Names []string // 中英文名称与城市，英文不区分大小写
+}
+
+// Flag 由 ISO 代码生成旗帜 emoji
+func (r Region) Flag() string {
+	var builder strings.Builder
+	for _, c := range strings.ToUpper(r.Code) {
+		builder.WriteRune(0x1F1E6 + c - 'A')
+	}
+	return builder.String()
+}
+
+// Pattern 匹配该地区的正则表达式
+func (r Region) Pattern() string {
+	alternatives := []string{regexp.QuoteMeta(r.Flag())}
+	if len(r.Names) > 0 {
+		names := make([]string, 0, len(r.Names))
+		for _, name := range r.Names {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		alternatives = append(alternatives, "(?i:"+strings.Join(names, "|")+")")
+	}
+	if len(r.Codes) > 0 {
+		alternatives = append(alternatives, "(?:^|[^A-Za-z])(?:"+strings.Join(r.Codes, "|")+")(?:[^A-Za-z]|$)")
+	}
+	return strings.Join(alternatives, "|")
+}
+
+// Regions 内置地区识别表，按顺序匹配，靠前的优先
+var Regions = []Region{
+	{Code: "HK", Codes: []string{"HK", "HKG"}, Names: []string{"香港", "Hong Kong", "HongKong"}},
+	{Code: "MO", Codes: []string{"MO"}, Names: []string{"澳门", "Macau", "Macao"}},
+	{Code: "TW", Codes: []string{"TW", "TWN"}, Names: []string{"台湾", "臺灣", "台北", "新北", "彰化", "Taiwan", "Taipei"}},
+	{Code: "JP", Codes: []string{"JP", "JPN"}, Names: []string{"日本", "东京", "大阪", "埼玉", "Japan", "Tokyo", "Osaka"}},
+	{Code: "SG", Codes: []string{"SG", "SGP"}, Names: []string{"新加坡", "狮城", "Singapore"}},
+	{Code: "KR", Codes: []string{"KR", "KOR"}, Names: []string{"韩国", "韓國", "首尔", "春川", "Korea", "Seoul"}},
+	{Code: "US", Codes: []string{"US", "USA"}, Names: []string{"美国", "美國", "洛杉矶", "硅谷", "圣何塞", "西雅图", "芝加哥", "纽约", "United States", "Los Angeles", "San Jose", "Seattle"}},
+	{Code: "CA", Codes: []string{"CA"}, Names: []string{"加拿大", "多伦多", "温哥华", "Canada", "Toronto"}},
+	{Code: "GB", Codes: []string{"UK", "GB", "GBR"}, Names: []string{"英国", "英國", "伦敦", "United Kingdom", "Britain", "London"}},
+	{Code: "DE", Codes: []string{"DE", "DEU"}, Names: []string{"德国", "德國", "法兰克福", "Germany", "Frankfurt"}},
+	{Code: "FR", Codes: []string{"FR", "FRA"}, Names: []string{"法国", "法國", "巴黎", "France", "Paris"}},
+	{Code: "NL", Codes: []string{"NL", "NLD"}, Names: []string{"荷兰", "阿姆斯特丹", "Netherlands", "Amsterdam"}},
+	{Code: "RU", Codes: []string{"RU", "RUS"}, Names: []string{"俄罗斯", "俄羅斯", "莫斯科", "伯力", "Russia", "Moscow"}},
+	{Code: "TR", Codes: []string{"TR", "TUR"}, Names: []string{"土耳其", "伊斯坦布尔", "Turkey", "Istanbul"}},
+	// 印度尼西亚 包含 印度，需排在印度之前
+	{Code: "ID", Codes: []string{"ID", "IDN"}, Names: []string{"印尼", "印度尼西亚", "雅加达", "Indonesia", "Jakarta"}},
+	{Code: "IN", Codes: []string{"IN", "IND"}, Names: []string{"印度", "孟买", "India", "Mumbai"}},
+	{Code: "AU", Codes: []string{"AU", "AUS"}, Names: []string{"澳大利亚", "澳洲", "悉尼", "Australia", "Sydney"}},
+	{Code: "MY", Codes: []string{"MY", "MYS"}, Names: []string{"马来西亚", "吉隆坡", "Malaysia"}},
+	{Code: "TH", Codes: []string{"TH", "THA"}, Names: []string{"泰国", "曼谷", "Thailand", "Bangkok"}},
+	{Code: "VN", Codes: []string{"VN", "VNM"}, Names: []string{"越南", "胡志明", "Vietnam"}},
+	{Code: "PH", Codes: []string{"PH", "PHL"}, Names: []string{"菲律宾", "马尼拉", "Philippines"}},
+	{Code: "AR", Codes: []string{"AR", "ARG"}, Names: []string{"阿根廷", "Argentina"}},
+	{Code: "BR", Codes: []string{"BR", "BRA"}, Names: []string{"巴西", "圣保罗", "Brazil"}},
+	{Code: "UA", Codes: []string{"UA", "UKR"}, Names: []string{"乌克兰", "Ukraine"}},
+	{Code: "CN", Codes: []string{"CN", "CHN"}, Names: []string{"中国", "回国", "China"}},
+}
+
+// RemoveEmoji 去掉名称中已有的 emoji，避免重复添加旗帜
+func RemoveEmoji(name string) string {
+	if strings.IndexFunc(name, isEmoji) < 0 {
+		return name
+	}
+	var builder strings.Builder
+	for _, r := range name {
+		if !isEmoji(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// isEmoji 判断字符是否属于 emoji 或其修饰符
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // 旗帜、表情、符号
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号与装饰符号
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // 旗帜标签
+		return true
+	case r == 0x200D || r == 0xFE0F || r == 0x20E3: // 连接符与变体选择符
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// internal/subscription/processor/rename.go
+package processor
+
+import (
+	"fmt"
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/utils"
+	"strings"
+)
+
+// RenameRule 节点重命名规则：regex@replacement
+type RenameRule struct {
+	Matcher     *utils.Matcher
+	Replacement string
+}
+
+// ParseRenameRules 解析 subconverter 的 rename 参数与 rename_node 配置，每项中的多条规则用 ` 分隔
+func ParseRenameRules(values ...string) ([]RenameRule, error) {
+	rules := make([]RenameRule, 0)
+	for _, value := range values {
+		for _, item := range strings.Split(value, "`") {
+			if item == "" {
+				continue
+			}
+			pattern, replacement, _ := strings.Cut(item, "@")
+			matcher, err := utils.CompileMatcher(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rename pattern %q: %v", pattern, err)
+			}
+			rules = append(rules, RenameRule{
+				Matcher:     matcher,
+				Replacement: replacement,
+			})
+		}
+	}
+	return rules, nil
+}
+
+// Rename 按顺序对节点名称应用重命名规则，并重新处理重名节点
+func Rename(nodes []*model.Node, rules []RenameRule) {
+	for _, node := range nodes {
+		for _, rule := range rules {
+			node.Name = rule.Matcher.ReplaceAllString(node.Name, rule.Replacement)
+		}
+	}
+	UniqueNames(nodes)
+}
+
+// NameOptions 节点名称处理选项
+type NameOptions struct {
+	RenameRules    []RenameRule
+	EmojiRules     []EmojiRule // 为空时使用内置地区表
+	AddEmoji       bool
+	RemoveOldEmoji bool
+}
+
+// ProcessNames 按 subconverter 的顺序处理节点名称：去掉旧 emoji、重命名、添加旗帜
+func ProcessNames(nodes []*model.Node, options NameOptions) {
+	if options.RemoveOldEmoji {
+		RemoveOldEmoji(nodes)
+	}
+	if len(options.RenameRules) > 0 {
+		Rename(nodes, options.RenameRules)
+	}
+	if options.AddEmoji {
+		AddEmoji(nodes, options.EmojiRules)
+	}
+}
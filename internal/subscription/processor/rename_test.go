@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"goconverter/internal/subscription/model"
+	"reflect"
+	"testing"
+)
+
+func TestProcessNames(t *testing.T) {
+	newNodes := func(names ...string) []*model.Node {
+		nodes := make([]*model.Node, 0, len(names))
+		for _, name := range names {
+			nodes = append(nodes, &model.Node{Name: name})
+		}
+		return nodes
+	}
+
+	customEmoji, err := ParseEmojiRules([]string{"(?i)iplc,🚀", "(流量|到期),🛑"})
+	if err != nil {
+		t.Fatalf("ParseEmojiRules() error = %v", err)
+	}
+	renameRules, err := ParseRenameRules("\\s*\\[.*?\\]\\s*@`IEPL@专线", "^(.*) 0?(\\d+)$@$1-$2")
+	if err != nil {
+		t.Fatalf("ParseRenameRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		nodes   []string
+		options NameOptions
+		want    []string
+	}{
+		{
+			name:    "builtin regions",
+			nodes:   []string{"香港 01", "HK-02", "Hong Kong 03", "日本 IEPL", "US LA", "Russia", "Plus 01", "🇯🇵 东京"},
+			options: NameOptions{AddEmoji: true},
+			want:    []string{"🇭🇰 香港 01", "🇭🇰 HK-02", "🇭🇰 Hong Kong 03", "🇯🇵 日本 IEPL", "🇺🇸 US LA", "🇷🇺 Russia", "Plus 01", "🇯🇵 东京"},
+		},
+		{
+			name:    "overlapping region names",
+			nodes:   []string{"印度尼西亚 01", "印度 01", "印尼 02"},
+			options: NameOptions{AddEmoji: true},
+			want:    []string{"🇮🇩 印度尼西亚 01", "🇮🇳 印度 01", "🇮🇩 印尼 02"},
+		},
+		{
+			name:    "remove old emoji",
+			nodes:   []string{"🇨🇳 香港 01", "🇭🇰 香港 01", "⚡️ SG 01"},
+			options: NameOptions{AddEmoji: true, RemoveOldEmoji: true},
+			want:    []string{"🇭🇰 香港 01", "🇭🇰 香港 01 2", "🇸🇬 SG 01"},
+		},
+		{
+			name:    "custom emoji rules",
+			nodes:   []string{"香港 IPLC", "剩余流量 10GB", "日本"},
+			options: NameOptions{AddEmoji: true, EmojiRules: customEmoji},
+			want:    []string{"🚀 香港 IPLC", "🛑 剩余流量 10GB", "日本"},
+		},
+		{
+			name:    "rename then dedup",
+			nodes:   []string{"[V1] 香港 01", "香港 01", "日本 IEPL 02"},
+			options: NameOptions{RenameRules: renameRules},
+			want:    []string{"香港-1", "香港-1 2", "日本 专线-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := newNodes(tt.nodes...)
+			ProcessNames(nodes, tt.options)
+			var got []string
+			for _, node := range nodes {
+				got = append(got, node.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProcessNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
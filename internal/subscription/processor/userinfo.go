@@ -0,0 +1,45 @@
+// internal/subscription/processor/userinfo.go
+package processor
+
+import (
+	"goconverter/internal/subscription/model"
+	"goconverter/internal/subscription/parser"
+)
+
+// MergeUserInfo 汇总所有订阅的流量与到期信息，都未提供时返回 nil
+func MergeUserInfo(sources []Source) *parser.UserInfo {
+	var merged *parser.UserInfo
+	for _, source := range sources {
+		if source.Info == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &parser.UserInfo{}
+		}
+		merged.Add(source.Info)
+	}
+	return merged
+}
+
+// InfoNode 生成名称为流量摘要的占位节点，让不显示订阅信息的客户端也能看到剩余流量
+func InfoNode(info *parser.UserInfo) *model.Node {
+	return &model.Node{
+		Type:     model.TypeSS,
+		Name:     info.Summary(),
+		Server:   "127.0.0.1",
+		Port:     1,
+		Cipher:   "aes-128-gcm",
+		Password: "info",
+		Settings: make(map[string]string),
+	}
+}
+
+// PrependInfoNode 在节点列表开头插入流量信息节点
+func PrependInfoNode(nodes []*model.Node, info *parser.UserInfo) []*model.Node {
+	if info == nil {
+		return nodes
+	}
+	nodes = append([]*model.Node{InfoNode(info)}, nodes...)
+	UniqueNames(nodes)
+	return nodes
+}
@@ -3,12 +3,25 @@ package utils
 
 import (
 	"encoding/base64"
+	"strings"
 )
 
+// Base64Decode 解码 base64，兼容标准/URL 字母表、有无填充以及换行
 func Base64Decode(s string) (string, error) {
-	bytes, err := base64.StdEncoding.DecodeString(s)
+	s = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimRight(s, "=")
+
+	bytes, err := base64.RawStdEncoding.DecodeString(s)
 	if err != nil {
-		return "", err
+		bytes, err = base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return "", err
+		}
 	}
 	return string(bytes), nil
 }
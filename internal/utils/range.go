@@ -0,0 +1,65 @@
+// internal/utils/range.go
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MatchRange 判断数字是否落在 subconverter 风格的范围表达式内
+//
+// 表达式以逗号分隔，支持 3、1-5、!3、!1-5、5-(小于等于)、5+(大于等于)
+func MatchRange(expr string, target int) bool {
+	match := false
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		negate := strings.HasPrefix(item, "!")
+		item = strings.TrimPrefix(item, "!")
+		if item == "" {
+			continue
+		}
+
+		var inRange bool
+		switch {
+		case strings.HasSuffix(item, "+"):
+			begin, err := strconv.Atoi(strings.TrimSuffix(item, "+"))
+			if err != nil {
+				continue
+			}
+			inRange = target >= begin
+		case strings.HasSuffix(item, "-"):
+			end, err := strconv.Atoi(strings.TrimSuffix(item, "-"))
+			if err != nil {
+				continue
+			}
+			inRange = target <= end
+		case strings.Index(item[1:], "-") >= 0:
+			// 第一个字符可能是负号
+			sep := strings.Index(item[1:], "-") + 1
+			begin, err1 := strconv.Atoi(item[:sep])
+			end, err2 := strconv.Atoi(item[sep+1:])
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			inRange = target >= begin && target <= end
+		default:
+			num, err := strconv.Atoi(item)
+			if err != nil {
+				continue
+			}
+			inRange = target == num
+		}
+
+		if negate {
+			// 取反条目：默认匹配，命中时不匹配
+			match = !inRange
+		} else if inRange {
+			match = true
+		}
+	}
+	return match
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestMatchRange(t *testing.T) {
+	tests := []struct {
+		expr   string
+		target int
+		want   bool
+	}{
+		{"443", 443, true},
+		{"443", 80, false},
+		{"1000-2000", 1500, true},
+		{"1000-2000", 2001, false},
+		{"80,443", 80, true},
+		{"!443", 80, true},
+		{"!443", 443, false},
+		{"!1-3", 2, false},
+		{"10000+", 20000, true},
+		{"1024-", 2048, false},
+		{"-1", -1, true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchRange(tt.expr, tt.target); got != tt.want {
+			t.Errorf("MatchRange(%q, %d) = %v, want %v", tt.expr, tt.target, got, tt.want)
+		}
+	}
+}
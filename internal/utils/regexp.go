@@ -0,0 +1,117 @@
+// internal/utils/regexp.go
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Matcher 兼容 subconverter/ACL4SSR 配置中的正则表达式
+//
+// Go 的 RE2 引擎不支持环视，而 ACL4SSR 常用 ^(?!.*日本).* 这种写法，
+// 这里把开头的 (?!...) / (?=...) 拆出来单独匹配。
+type Matcher struct {
+	pattern  string
+	positive *regexp.Regexp
+	requires []*regexp.Regexp // (?=...) 必须匹配
+	excludes []*regexp.Regexp // (?!...) 不能匹配
+}
+
+// CompileMatcher 编译正则表达式，支持开头的前瞻断言
+func CompileMatcher(pattern string) (*Matcher, error) {
+	m := &Matcher{pattern: pattern}
+
+	rest := pattern
+	anchored := strings.HasPrefix(rest, "^")
+	rest = strings.TrimPrefix(rest, "^")
+	for strings.HasPrefix(rest, "(?!") || strings.HasPrefix(rest, "(?=") {
+		end := closingParen(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced lookahead in %q", pattern)
+		}
+		// 前瞻断言从当前位置开始匹配，展开后需要锚定开头
+		inner, err := regexp.Compile("^(?:" + rest[3:end] + ")")
+		if err != nil {
+			return nil, err
+		}
+		if rest[2] == '!' {
+			m.excludes = append(m.excludes, inner)
+		} else {
+			m.requires = append(m.requires, inner)
+		}
+		rest = rest[end+1:]
+	}
+
+	if len(m.excludes) == 0 && len(m.requires) == 0 {
+		rest = pattern
+	} else if anchored || rest != "" {
+		// 断言只在开头出现时才拆分，剩余部分视为从开头匹配
+		rest = "^(?:" + rest + ")"
+	}
+
+	positive, err := regexp.Compile(rest)
+	if err != nil {
+		return nil, err
+	}
+	m.positive = positive
+
+	return m, nil
+}
+
+// MustCompileMatcher 同 CompileMatcher，编译失败时 panic
+func MustCompileMatcher(pattern string) *Matcher {
+	m, err := CompileMatcher(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MatchString 判断字符串是否匹配
+func (m *Matcher) MatchString(s string) bool {
+	for _, re := range m.excludes {
+		if re.MatchString(s) {
+			return false
+		}
+	}
+	for _, re := range m.requires {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	return m.positive.MatchString(s)
+}
+
+// ReplaceAllString 使用正则替换，前瞻断言不匹配时原样返回
+func (m *Matcher) ReplaceAllString(src, repl string) string {
+	if len(m.excludes) > 0 || len(m.requires) > 0 {
+		if !m.MatchString(src) {
+			return src
+		}
+	}
+	return m.positive.ReplaceAllString(src, repl)
+}
+
+func (m *Matcher) String() string {
+	return m.pattern
+}
+
+// closingParen 返回与开头括号匹配的右括号下标
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    bool
+	}{
+		{"(港|HK)", "香港 01", true},
+		{"(港|HK)", "日本 01", false},
+		{"^(?!.*日本).*", "香港 01", true},
+		{"^(?!.*日本).*", "日本 01", false},
+		{"(?=.*IPLC)(?!.*日本)", "香港 IPLC", true},
+		{"(?=.*IPLC)(?!.*日本)", "日本 IPLC", false},
+		{"^(?!.*(剩余|到期))HK", "HK 01", true},
+		{"^(?!.*(剩余|到期))HK", "HK 剩余流量", false},
+	}
+
+	for _, tt := range tests {
+		m, err := CompileMatcher(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompileMatcher(%q) error = %v", tt.pattern, err)
+		}
+		if got := m.MatchString(tt.input); got != tt.want {
+			t.Errorf("%q.MatchString(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}